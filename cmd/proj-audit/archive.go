@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ErikOlson/proj-audit/internal/analyze"
+	"github.com/ErikOlson/proj-audit/internal/archive"
+	"github.com/ErikOlson/proj-audit/internal/filter"
+	"github.com/ErikOlson/proj-audit/internal/model"
+	"github.com/ErikOlson/proj-audit/internal/render"
+)
+
+// defaultArchiveCategories are archived when no filter flag is given.
+var defaultArchiveCategories = []string{"Archived"}
+
+func runArchive(args []string) {
+	if len(args) > 0 && args[0] == "restore" {
+		runRestore(args[1:])
+		return
+	}
+
+	fs := flag.NewFlagSet("archive", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	dest := fs.String("dest", "proj-audit-archive", "directory to write archives to")
+	move := fs.Bool("move", false, "move each original into --dest once its archive has been verified")
+	dryRun := fs.Bool("dry-run", false, "list the projects that would be archived and exit")
+	yes := fs.Bool("yes", false, "with --move, move originals without asking for confirmation")
+	fs.Parse(args)
+
+	cfg := flags.config()
+	f := flags.filter()
+	if f.IsEmpty() {
+		f.Categories = defaultArchiveCategories
+	}
+
+	var projects []*model.Project
+	for _, p := range outermostProjects(audit(cfg), f) {
+		repo, err := analyze.GitDirtyTree(p.Path)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "skipping %s: git status failed in %s: %v\n", p.Path, repo, err)
+		case repo != "":
+			fmt.Fprintf(os.Stderr, "skipping %s: uncommitted changes in %s\n", p.Path, repo)
+		default:
+			projects = append(projects, p)
+		}
+	}
+	if len(projects) == 0 {
+		fmt.Println("No projects to archive.")
+		return
+	}
+
+	fmt.Printf("Projects to archive into %s:\n", *dest)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range projects {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", p.Category, p.Path, render.FormatBytes(p.Metrics.SourceBytes))
+	}
+	tw.Flush()
+	if *dryRun {
+		return
+	}
+	if *move && !*yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("Archive %d projects and move them into %s? [y/N] ", len(projects), *dest)) {
+		fmt.Println("Aborted; nothing was archived.")
+		return
+	}
+
+	exclude := cfg.ArtifactDirs()
+	failed := 0
+	for _, p := range projects {
+		result, err := archive.Create(p, *dest, exclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "archive %s: %v\n", p.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s -> %s (%d files, %s)\n", p.Path, result.Archive, result.Manifest.Files, render.FormatBytes(result.Manifest.Bytes))
+		if !*move {
+			continue
+		}
+		if _, err := archive.Verify(result.Archive); err != nil {
+			fmt.Fprintf(os.Stderr, "keeping %s: %v\n", p.Path, err)
+			failed++
+			continue
+		}
+		moved, err := archive.Move(result, *dest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s moved to %s\n", result.Manifest.Path, moved)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("archive restore", flag.ExitOnError)
+	to := fs.String("to", "", "directory to restore into (default: the project's original path)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: proj-audit archive restore [--to dir] <archive.tar.gz>")
+	}
+
+	target, err := archive.Restore(fs.Arg(0), *to)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	fmt.Printf("Restored %s\n", target)
+}
+
+// outermostProjects returns the matching projects that are not nested inside
+// another matching project, since archiving the outer one already covers them.
+func outermostProjects(root *model.Node, f filter.Filter) []*model.Project {
+	var out []*model.Project
+	var visit func(node *model.Node)
+	visit = func(node *model.Node) {
+		if node.Project != nil && f.Match(node.Project) {
+			out = append(out, node.Project)
+			return
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	if root != nil {
+		visit(root)
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/analyze"
+	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/filter"
+	"github.com/ErikOlson/proj-audit/internal/model"
+	"github.com/ErikOlson/proj-audit/internal/scan"
+	"github.com/ErikOlson/proj-audit/internal/score"
+)
+
+// auditFlags are the scan, config and filter flags shared by every command.
+type auditFlags struct {
+	fs          *flag.FlagSet
+	bindings    map[string]string
+	configPath  *string
+	category    *string
+	framework   *string
+	projectType *string
+	tag         *string
+	owner       *string
+	status      *string
+}
+
+// configFlags maps the flags that override a config value to its key.
+var configFlags = map[string]string{
+	"root":           "root",
+	"max-depth":      "maxDepth",
+	"include-hidden": "includeHidden",
+	"languages":      "languagesFile",
+	"ci-markers":     "ciFile",
+	"frameworks":     "frameworksFile",
+	"versions":       "versionsFile",
+	"registry":       "registryFile",
+	"profile":        "profile",
+}
+
+func registerAuditFlags(fs *flag.FlagSet) *auditFlags {
+	fs.String("root", "", "root directory to scan (default: config or current directory)")
+	fs.Int("max-depth", 0, "maximum directory depth to scan (0 = unlimited)")
+	fs.String("ignore", "", "comma-separated directories to ignore (appended to config)")
+	fs.Bool("include-hidden", false, "include dot-prefixed directories")
+	fs.String("languages", "", "path to a languages YAML file")
+	fs.String("ci-markers", "", "path to a CI marker YAML file")
+	fs.String("frameworks", "", "path to a framework rules YAML file")
+	fs.String("versions", "", "path to a YAML/JSON table of current toolchain and package versions")
+	fs.String("registry", "", "path to the tags and notes registry (default: proj-audit/registry.json in the user config dir)")
+	fs.String("profile", "", "scoring profile to use (default, showcase, cleanup, risk or one from the config)")
+	fs.String("disable-analyzers", "", "comma-separated analyzers to disable (git,fs,lang,manifest,staleness,framework,description)")
+
+	bindings := make(map[string]string, len(configFlags))
+	for name, key := range configFlags {
+		bindings[name] = key
+	}
+	return &auditFlags{
+		fs:          fs,
+		bindings:    bindings,
+		configPath:  fs.String("config", "", "path to a JSON or YAML config file (PROJ_AUDIT_CONFIG sets it too)"),
+		category:    fs.String("category", "", "comma-separated categories to include"),
+		framework:   fs.String("framework", "", "comma-separated frameworks to include"),
+		projectType: fs.String("type", "", "comma-separated project types to include (web,cli,library,infra)"),
+		tag:         fs.String("tag", "", "comma-separated tags to include (from .proj-audit.yaml or the registry)"),
+		owner:       fs.String("owner", "", "comma-separated registry owners to include"),
+		status:      fs.String("status", "", "comma-separated registry statuses to include"),
+	}
+}
+
+// bind makes a command's own flag override the config key.
+func (f *auditFlags) bind(name, key string) {
+	f.bindings[name] = key
+}
+
+// config layers the defaults, the config file and the flags given on the
+// command line, applies the scoring profile, and resolves the language, CI
+// and framework tables.
+func (f *auditFlags) config() config.Config {
+	cfg := f.discover()
+	if err := cfg.ApplyProfile(); err != nil {
+		log.Fatalf("load scoring profile: %v", err)
+	}
+	if err := cfg.ResolveTables(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	return cfg
+}
+
+// discover layers the config files, environment and flags, before the
+// profile is applied and the tables are resolved.
+func (f *auditFlags) discover() config.Config {
+	flagCfg, err := config.FromMap(f.values(), "flags")
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	cfg, err := config.Discover(config.Sources{File: *f.configPath, Environ: os.Environ(), Flags: flagCfg})
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+// values collects the config values set by flags given on the command line.
+func (f *auditFlags) values() map[string]interface{} {
+	values := make(map[string]interface{})
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "ignore":
+			values["ignoreDirs"] = parseList(fl.Value.String())
+		case "disable-analyzers":
+			analyzers := make(map[string]interface{})
+			for _, name := range parseList(fl.Value.String()) {
+				analyzers[strings.ToLower(name)] = false
+			}
+			values["analyzers"] = analyzers
+		default:
+			if key, ok := f.bindings[fl.Name]; ok {
+				values[key] = fl.Value.(flag.Getter).Get()
+			}
+		}
+	})
+	return values
+}
+
+func (f *auditFlags) filter() filter.Filter {
+	return filter.Filter{
+		Categories: parseList(*f.category),
+		Frameworks: parseList(*f.framework),
+		Types:      parseList(*f.projectType),
+		Tags:       parseList(*f.tag),
+		Owners:     parseList(*f.owner),
+		Statuses:   parseList(*f.status),
+	}
+}
+
+// audit scans cfg.Root and annotates every project with metrics, scores, a
+// category and its registry entry.
+func audit(cfg config.Config) *model.Node {
+	scanner := scan.NewDefaultScanner(cfg.AllIgnoreDirs(), cfg.IncludeHidden)
+
+	tree, err := scanner.Scan(cfg.Root, cfg.MaxDepth)
+	if err != nil {
+		log.Fatalf("scan error: %v", err)
+	}
+	for _, warning := range scanner.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	analyzer, scorer, describer := pipeline(cfg)
+	if err := annotateTree(tree, analyzer, scorer, describer); err != nil {
+		log.Fatalf("annotate error: %v", err)
+	}
+	reg := openRegistry(cfg.RegistryFile)
+	for _, p := range filter.Projects(tree, filter.Filter{}) {
+		reg.Apply(p)
+	}
+	score.Rank(filter.Projects(tree, filter.Filter{}))
+	return tree
+}
+
+// pipeline builds the enabled analyzers, the scorer and the describer.
+func pipeline(cfg config.Config) (analyze.Analyzer, score.Scorer, analyze.Describer) {
+	analyzerToggles := cfg.EffectiveAnalyzers()
+	ignoreDirs := cfg.AllIgnoreDirs()
+
+	var analyzersList []analyze.Analyzer
+	if analyzerToggles["git"] {
+		analyzersList = append(analyzersList, analyze.NewGitAnalyzer())
+	}
+	if analyzerToggles["fs"] {
+		analyzersList = append(analyzersList, analyze.NewFsAnalyzer(ignoreDirs, cfg.IncludeHidden, cfg.CIMarkers(), cfg.ArtifactDirs(), testRules(cfg.Languages)))
+	}
+	if analyzerToggles["lang"] {
+		analyzersList = append(analyzersList, analyze.NewLangAnalyzer(ignoreDirs, cfg.IncludeHidden, cfg.ExtensionMapping(), testRules(cfg.Languages)))
+	}
+	if analyzerToggles["manifest"] {
+		analyzersList = append(analyzersList, analyze.NewManifestAnalyzer())
+	}
+	if analyzerToggles["framework"] {
+		analyzersList = append(analyzersList, analyze.NewFrameworkAnalyzer(ignoreDirs, cfg.IncludeHidden, frameworkRules(cfg.Frameworks)))
+	}
+	if analyzerToggles["staleness"] {
+		table, err := versionTable(cfg.VersionsFile)
+		if err != nil {
+			log.Fatalf("load versions: %v", err)
+		}
+		analyzersList = append(analyzersList, analyze.NewStalenessAnalyzer(table))
+	}
+	if len(analyzersList) == 0 {
+		log.Fatalf("no analyzers enabled; enable at least one")
+	}
+	analyzer := analyze.NewCompositeAnalyzer(analyzersList...)
+	scorer := score.NewDefaultScorer(cfg.Scoring)
+	if err := scorer.Validate(); err != nil {
+		log.Fatalf("invalid scoring config: %v", err)
+	}
+
+	var describer analyze.Describer
+	if analyzerToggles["description"] {
+		describer = analyze.NewDescriptionAnalyzer()
+	}
+
+	return analyzer, scorer, describer
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ErikOlson/proj-audit/internal/clean"
+	"github.com/ErikOlson/proj-audit/internal/render"
+)
+
+// defaultCleanCategories are cleaned when no filter flag is given.
+var defaultCleanCategories = []string{"Archived", "Experiment"}
+
+func runClean(args []string) {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "list what would be deleted and exit")
+	yes := fs.Bool("yes", false, "delete without asking for confirmation")
+	logPath := fs.String("log", "", "path of the JSON deletion log (default: proj-audit-clean-<timestamp>.json)")
+	nonGit := fs.Bool("non-git", false, "also clean projects outside git, whose bin, build or vendor directories may be hand-written")
+	fs.Parse(args)
+
+	cfg := flags.config()
+	f := flags.filter()
+	if f.IsEmpty() {
+		f.Categories = defaultCleanCategories
+	}
+
+	plan := clean.NewPlan(audit(cfg), f, *nonGit)
+	printPlan(os.Stdout, plan)
+	if len(plan.Targets) == 0 || *dryRun {
+		return
+	}
+
+	if !*yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("Delete %d directories (%s)? [y/N] ", len(plan.Targets), render.FormatBytes(plan.TotalBytes()))) {
+		fmt.Println("Aborted; nothing was deleted.")
+		return
+	}
+
+	result := clean.Execute(plan)
+	path := *logPath
+	if path == "" {
+		path = fmt.Sprintf("proj-audit-clean-%s.json", result.Time.Format("20060102T150405"))
+	}
+	if err := clean.WriteLog(path, result); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	failed := 0
+	for _, entry := range result.Entries {
+		if !entry.Deleted {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to delete %s: %s\n", entry.Path, entry.Error)
+		}
+	}
+	fmt.Printf("Freed %s from %d directories; log written to %s\n", render.FormatBytes(result.FreedBytes), len(result.Entries)-failed, path)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printPlan(w io.Writer, plan clean.Plan) {
+	if len(plan.Targets) == 0 {
+		fmt.Fprintln(w, "No artifact directories to delete.")
+	} else {
+		fmt.Fprintf(w, "Artifact directories to delete (%s in %d directories):\n", render.FormatBytes(plan.TotalBytes()), len(plan.Targets))
+		if plan.NonGit {
+			fmt.Fprintln(w, "  --non-git: projects outside git are included; check their directories are generated")
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, t := range plan.Targets {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", t.Category, t.Path, render.FormatBytes(t.Bytes))
+		}
+		tw.Flush()
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Fprintln(w, "Skipped:")
+		nonGit := false
+		for _, s := range plan.Skipped {
+			fmt.Fprintf(w, "  %s: %s\n", s.Path, s.Reason)
+			nonGit = nonGit || s.Reason == clean.ReasonNotGit
+		}
+		if nonGit {
+			fmt.Fprintln(w, "Pass --non-git to clean projects outside git as well.")
+		}
+	}
+}
+
+func confirm(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprint(out, prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
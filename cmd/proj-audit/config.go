@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/score"
+)
+
+func runConfig(args []string) {
+	if len(args) == 0 {
+		log.Fatalf("usage: proj-audit config show|validate [flags]")
+	}
+	switch args[0] {
+	case "show":
+		runConfigShow(args[1:])
+	case "validate":
+		runConfigValidate(args[1:])
+	default:
+		log.Fatalf("unknown config command %q (expected show or validate)", args[0])
+	}
+}
+
+func runConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	fs.String("format", "", "output format to record in the config (tree|markdown|json)")
+	flags.bind("format", "format")
+	withOrigin := fs.Bool("origin", false, "print every effective value with the source that set it")
+	fs.Parse(args)
+
+	cfg := flags.config()
+	if !*withOrigin {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg); err != nil {
+			log.Fatalf("encode config: %v", err)
+		}
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", setting.Key, setting.Origin, setting.Value)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// runConfigValidate reports every problem in the discovered config files and
+// the config they merge to, and exits 1 if there are any.
+func runConfigValidate(args []string) {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	fs.String("format", "", "output format to record in the config (tree|markdown|json)")
+	flags.bind("format", "format")
+	fs.Parse(args)
+
+	cfg := flags.discover()
+	problems := cfg.Validate(func(sc *config.ScoringConfig) error {
+		return score.NewDefaultScorer(sc).Validate()
+	})
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(problems))
+		os.Exit(1)
+	}
+	files := cfg.Files()
+	if len(files) == 0 {
+		files = []string{"built-in defaults only"}
+	}
+	fmt.Printf("config is valid (%s)\n", strings.Join(files, ", "))
+}
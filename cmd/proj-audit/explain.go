@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+	"github.com/ErikOlson/proj-audit/internal/render"
+	"github.com/ErikOlson/proj-audit/internal/scan"
+)
+
+func runExplain(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	formatFlag := fs.String("format", "text", "output format: text|json")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: proj-audit explain [flags] <path>")
+	}
+
+	path, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("resolve path: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		log.Fatalf("%s is not a directory", path)
+	}
+
+	cfg := flags.config()
+	analyzer, scorer, describer := pipeline(cfg)
+	project := &model.Project{Path: path, Name: filepath.Base(path)}
+	if _, err := scan.ApplyProjectFile(project); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := annotateProject(project, path, analyzer, scorer, describer); err != nil {
+		log.Fatalf("analyze error: %v", err)
+	}
+	openRegistry(cfg.RegistryFile).Apply(project)
+
+	switch *formatFlag {
+	case "text":
+		err = render.WriteExplanation(os.Stdout, project)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(project)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q (expected text|json)\n", *formatFlag)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("render error: %v", err)
+	}
+}
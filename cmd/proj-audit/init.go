@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/config"
+)
+
+// generatedDirs are build, cache and tool directories beyond the default
+// ignore list; init adds the ones it finds under the root to ignoreDirs.
+var generatedDirs = []string{
+	".angular", ".dart_tool", ".gradle", ".mypy_cache", ".next", ".nuxt",
+	".parcel-cache", ".pytest_cache", ".stack-work", ".svelte-kit", ".terraform",
+	".tox", ".turbo", "DerivedData", "Pods", "_build", "coverage",
+	"dist-newstyle", "zig-cache", "zig-out",
+}
+
+// initWalkLimit caps how many directory entries init inspects, so running it
+// from a large tree stays quick.
+const initWalkLimit = 50000
+
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	root := fs.String("root", ".", "directory to inspect for languages and generated directories")
+	output := fs.String("output", "", "file to write, or - for stdout (default: config.yaml in the proj-audit user config directory)")
+	force := fs.Bool("force", false, "overwrite an existing config file")
+	detect := fs.Bool("detect", true, "tune languages and ignoreDirs to what is found under --root")
+	fs.Parse(args)
+
+	var opts config.StarterOptions
+	if *detect {
+		var err error
+		if opts, err = detectStarter(config.ExpandHome(*root)); err != nil {
+			log.Fatalf("inspect %s: %v", *root, err)
+		}
+	}
+	data := config.Starter(opts)
+	if *output == "-" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+
+	path := config.ExpandHome(*output)
+	if path == "" {
+		var err error
+		if path, _, err = config.UserConfigFile(); err != nil {
+			log.Fatalf("locate user config: %v", err)
+		}
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		log.Fatalf("%s is a JSON config; init writes YAML, so pass --output with a .yaml path", path)
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		log.Fatalf("%s already exists; pass --force to overwrite it", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Fatalf("create config dir: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalf("write config: %v", err)
+	}
+	fmt.Printf("Wrote %s; check edits with proj-audit config validate.\n", path)
+}
+
+// detectStarter walks root for the built-in languages with files there and
+// the generated directories present. Ignored and hidden directories are not
+// descended into.
+func detectStarter(root string) (config.StarterOptions, error) {
+	defaults := config.DefaultConfig()
+	byExt := make(map[string]string)
+	for name, lang := range defaults.Languages {
+		for _, ext := range lang.Extensions {
+			byExt[strings.ToLower(ext)] = name
+		}
+	}
+	skip := make(map[string]bool)
+	for _, dir := range defaults.AllIgnoreDirs() {
+		skip[dir] = true
+	}
+
+	languages := make(map[string]bool)
+	found := make(map[string]bool)
+	seen := 0
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if seen++; seen > initWalkLimit {
+			return filepath.SkipAll
+		}
+		name := entry.Name()
+		if !entry.IsDir() {
+			if lang, ok := byExt[strings.ToLower(filepath.Ext(name))]; ok {
+				languages[lang] = true
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+		for _, dir := range generatedDirs {
+			if name == dir {
+				found[dir] = true
+				return filepath.SkipDir
+			}
+		}
+		if skip[name] || strings.HasPrefix(name, ".") {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, filepath.SkipAll) {
+		return config.StarterOptions{}, err
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		abs = root
+	}
+	opts := config.StarterOptions{Root: abs}
+	for lang := range languages {
+		opts.Languages = append(opts.Languages, lang)
+	}
+	for dir := range found {
+		opts.IgnoreDirs = append(opts.IgnoreDirs, dir)
+	}
+	sort.Strings(opts.Languages)
+	sort.Strings(opts.IgnoreDirs)
+	return opts, nil
+}
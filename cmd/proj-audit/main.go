@@ -9,97 +9,64 @@ import (
 
 	"github.com/ErikOlson/proj-audit/internal/analyze"
 	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/filter"
+	"github.com/ErikOlson/proj-audit/internal/model"
 	"github.com/ErikOlson/proj-audit/internal/render"
 	"github.com/ErikOlson/proj-audit/internal/scan"
 	"github.com/ErikOlson/proj-audit/internal/score"
 )
 
 func main() {
-	rootFlag := flag.String("root", "", "root directory to scan (default: config or current directory)")
-	maxDepthFlag := flag.Int("max-depth", -1, "maximum directory depth to scan (-1 = use config, 0 = unlimited)")
-	formatFlag := flag.String("format", "", "output format: tree|markdown|json (default from config)")
-	configPath := flag.String("config", "", "path to JSON config file")
-	ignoreFlag := flag.String("ignore", "", "comma-separated directories to ignore (appended to config)")
-	includeHidden := flag.Bool("include-hidden", false, "include dot-prefixed directories")
-	languagesFile := flag.String("languages", "", "path to a languages YAML file")
-	disableAnalyzers := flag.String("disable-analyzers", "", "comma-separated analyzers to disable (git,fs,lang)")
-	flag.Parse()
-
-	cfg := config.DefaultConfig()
-
-	if *configPath != "" {
-		fileCfg, err := config.Load(*configPath)
-		if err != nil {
-			log.Fatalf("load config: %v", err)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "clean":
+			runClean(os.Args[2:])
+			return
+		case "archive":
+			runArchive(os.Args[2:])
+			return
+		case "explain":
+			runExplain(os.Args[2:])
+			return
+		case "tag":
+			runTag(os.Args[2:])
+			return
+		case "note":
+			runNote(os.Args[2:])
+			return
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "init":
+			runInit(os.Args[2:])
+			return
 		}
-		cfg = config.Merge(cfg, fileCfg)
-	}
-
-	if *rootFlag != "" {
-		cfg.Root = *rootFlag
-	}
-	if *maxDepthFlag >= 0 {
-		cfg.MaxDepth = *maxDepthFlag
-	}
-	if *formatFlag != "" {
-		cfg.Format = *formatFlag
-	}
-	if *ignoreFlag != "" {
-		cfg.IgnoreDirs = append(cfg.IgnoreDirs, parseList(*ignoreFlag)...)
-	}
-	if *includeHidden {
-		cfg.IncludeHidden = true
 	}
-	if *languagesFile != "" {
-		cfg.LanguagesFile = *languagesFile
-	}
-
-	langs, err := cfg.ResolveLanguages()
-	if err != nil {
-		log.Fatalf("load languages: %v", err)
-	}
-	cfg.Languages = langs
-	if cfg.Analyzers == nil {
-		cfg.Analyzers = make(map[string]bool)
-	}
-	for _, name := range parseList(*disableAnalyzers) {
-		cfg.Analyzers[strings.ToLower(name)] = false
-	}
-	analyzerToggles := cfg.EffectiveAnalyzers()
-
-	ignoreDirs := cfg.AllIgnoreDirs()
+	runReport(os.Args[1:])
+}
 
-	scanner := scan.NewDefaultScanner(ignoreDirs, cfg.IncludeHidden)
+func runReport(args []string) {
+	fs := flag.NewFlagSet("proj-audit", flag.ExitOnError)
+	flags := registerAuditFlags(fs)
+	fs.String("format", "", "output format: tree|markdown|json (default from config)")
+	describe := fs.Bool("describe", false, "append project descriptions in tree output")
+	color := fs.String("color", "auto", "color tree output by category: auto|always|never")
+	flags.bind("format", "format")
+	fs.Parse(args)
 
-	tree, err := scanner.Scan(cfg.Root, cfg.MaxDepth)
-	if err != nil {
-		log.Fatalf("scan error: %v", err)
-	}
+	cfg := flags.config()
 
-	var analyzersList []analyze.Analyzer
-	if analyzerToggles["git"] {
-		analyzersList = append(analyzersList, analyze.NewGitAnalyzer())
-	}
-	if analyzerToggles["fs"] {
-		analyzersList = append(analyzersList, analyze.NewFsAnalyzer(ignoreDirs, cfg.IncludeHidden))
-	}
-	if analyzerToggles["lang"] {
-		analyzersList = append(analyzersList, analyze.NewLangAnalyzer(ignoreDirs, cfg.IncludeHidden, cfg.ExtensionMapping()))
-	}
-	if len(analyzersList) == 0 {
-		log.Fatalf("no analyzers enabled; enable at least one")
-	}
-	analyzer := analyze.NewCompositeAnalyzer(analyzersList...)
-	scorer := score.NewDefaultScorer(cfg.Scoring)
-
-	if err := annotateTree(tree, analyzer, scorer); err != nil {
-		log.Fatalf("annotate error: %v", err)
-	}
+	tree := filter.Prune(audit(cfg), flags.filter())
 
 	var r render.Renderer
 	switch cfg.Format {
 	case "tree":
-		r = render.NewTreeRenderer()
+		tr := render.NewTreeRenderer()
+		tr.ShowDescriptions = *describe
+		if useColor(*color) {
+			tr.Colors = cfg.Scoring.Categories.Colors()
+		}
+		r = tr
 	case "markdown":
 		r = render.NewMarkdownRenderer()
 	case "json":
@@ -114,36 +81,131 @@ func main() {
 	}
 }
 
+// useColor resolves the --color flag; "auto" colors only when stdout is a
+// terminal and NO_COLOR is unset.
+func useColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // annotateTree is a placeholder; the agent should move this into an appropriate package
 // or keep it here if that remains simplest.
-func annotateTree(root *scan.Node, analyzer analyze.Analyzer, scorer score.Scorer) error {
+func annotateTree(root *scan.Node, analyzer analyze.Analyzer, scorer score.Scorer, describer analyze.Describer) error {
 	if root == nil {
 		return nil
 	}
 
 	var visit func(node *scan.Node) error
 	visit = func(node *scan.Node) error {
-		if node.Project != nil && analyzer != nil && scorer != nil {
-			metrics, err := analyzer.Analyze(node.Path)
-			if err != nil {
+		if node.Project != nil {
+			if err := annotateProject(node.Project, node.Path, analyzer, scorer, describer); err != nil {
 				return err
 			}
-			node.Project.Metrics = metrics
-			scores := scorer.Score(metrics)
-			node.Project.Scores = scores
-			node.Project.Category = scorer.Categorize(scores, metrics)
 		}
+		var sourceBytes, artifactBytes int64
 		for _, child := range node.Children {
 			if err := visit(child); err != nil {
 				return err
 			}
+			sourceBytes += child.SourceBytes
+			artifactBytes += child.ArtifactBytes
+		}
+		// A project's own walk already covers everything beneath it, so
+		// nested projects are not counted twice.
+		if node.Project != nil {
+			sourceBytes = node.Project.Metrics.SourceBytes
+			artifactBytes = node.Project.Metrics.ArtifactBytes
 		}
+		node.SourceBytes = sourceBytes
+		node.ArtifactBytes = artifactBytes
 		return nil
 	}
 
 	return visit(root)
 }
 
+func annotateProject(project *model.Project, path string, analyzer analyze.Analyzer, scorer score.Scorer, describer analyze.Describer) error {
+	if analyzer != nil && scorer != nil {
+		metrics, err := analyzer.Analyze(path)
+		if err != nil {
+			return err
+		}
+		project.Metrics = metrics
+		project.Scores = scorer.Score(metrics)
+		explanation := scorer.Explain(project)
+		project.Explanation = &explanation
+		project.Category = scorer.Categorize(project)
+	}
+	if describer != nil && project.Description == "" {
+		description, err := describer.Describe(path)
+		if err != nil {
+			return err
+		}
+		project.Description = description
+	}
+	return nil
+}
+
+func testRules(languages map[string]config.LanguageConfig) map[string]analyze.TestRule {
+	rules := make(map[string]analyze.TestRule, len(languages))
+	for name, lang := range languages {
+		rules[name] = analyze.TestRule{
+			Files:   lang.TestFiles,
+			Dirs:    lang.TestDirs,
+			Markers: lang.TestMarkers,
+		}
+	}
+	return rules
+}
+
+func frameworkRules(frameworks map[string]config.FrameworkConfig) map[string]analyze.FrameworkRule {
+	rules := make(map[string]analyze.FrameworkRule, len(frameworks))
+	for name, fw := range frameworks {
+		rules[name] = analyze.FrameworkRule{
+			Type:         fw.Type,
+			Dependencies: fw.Dependencies,
+			Files:        fw.Files,
+			Imports:      fw.Imports,
+			Extensions:   fw.Extensions,
+		}
+	}
+	return rules
+}
+
+func versionTable(path string) (analyze.VersionTable, error) {
+	if path == "" {
+		return analyze.VersionTable{}, nil
+	}
+	loaded, err := config.LoadVersionsFile(path)
+	if err != nil {
+		return analyze.VersionTable{}, err
+	}
+	table := analyze.VersionTable{
+		Toolchains: make(map[string]string, len(loaded.Toolchains)),
+		Packages:   make(map[string]map[string]string, len(loaded.Packages)),
+		MinorLag:   loaded.MinorLag,
+	}
+	for name, version := range loaded.Toolchains {
+		table.Toolchains[name] = string(version)
+	}
+	for ecosystem, packages := range loaded.Packages {
+		table.Packages[ecosystem] = make(map[string]string, len(packages))
+		for name, version := range packages {
+			table.Packages[ecosystem][name] = string(version)
+		}
+	}
+	return table, nil
+}
+
 func parseList(input string) []string {
 	if input == "" {
 		return nil
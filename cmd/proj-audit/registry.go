@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ErikOlson/proj-audit/internal/analyze"
+	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/model"
+	"github.com/ErikOlson/proj-audit/internal/registry"
+)
+
+func runTag(args []string) {
+	fs := flag.NewFlagSet("tag", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a JSON or YAML config file (for registryFile)")
+	registryFile := fs.String("registry", "", "path to the registry (default: proj-audit/registry.json in the user config dir)")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatalf("usage: proj-audit tag [flags] <path> [+tag|-tag ...]")
+	}
+
+	reg := openRegistry(registryPath(*configPath, *registryFile))
+	path, remote := projectKey(fs.Arg(0))
+	entry := reg.Entry(path, remote)
+	if fs.NArg() > 1 {
+		if err := entry.Tag(fs.Args()[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := reg.Save(); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	fmt.Printf("%s: %s\n", path, strings.Join(entry.Tags, ", "))
+}
+
+func runNote(args []string) {
+	fs := flag.NewFlagSet("note", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a JSON or YAML config file (for registryFile)")
+	registryFile := fs.String("registry", "", "path to the registry (default: proj-audit/registry.json in the user config dir)")
+	owner := fs.String("owner", "", "set the project's owner")
+	status := fs.String("status", "", "set the project's status")
+	clear := fs.Bool("clear", false, "remove the project's existing notes")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatalf("usage: proj-audit note [--owner name] [--status status] [--clear] <path> [text]")
+	}
+
+	reg := openRegistry(registryPath(*configPath, *registryFile))
+	path, remote := projectKey(fs.Arg(0))
+	entry := reg.Entry(path, remote)
+	changed := false
+	if *owner != "" {
+		entry.Owner = *owner
+		changed = true
+	}
+	if *status != "" {
+		entry.Status = *status
+		changed = true
+	}
+	if *clear {
+		entry.Notes = nil
+		changed = true
+	}
+	if text := strings.TrimSpace(strings.Join(fs.Args()[1:], " ")); text != "" {
+		entry.Notes = append(entry.Notes, model.Note{Time: time.Now().UTC(), Text: text})
+		changed = true
+	}
+	if changed {
+		if err := reg.Save(); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	fmt.Printf("%s\n", path)
+	if entry.Owner != "" || entry.Status != "" {
+		fmt.Printf("  owner: %s | status: %s\n", entry.Owner, entry.Status)
+	}
+	for _, note := range entry.Notes {
+		fmt.Printf("  %s  %s\n", note.Time.Local().Format("2006-01-02"), note.Text)
+	}
+}
+
+// registryPath resolves the registry location from the --registry flag or the
+// discovered config's registryFile; empty means the default.
+func registryPath(configPath, flagValue string) string {
+	values := make(map[string]interface{})
+	if flagValue != "" {
+		values["registryFile"] = flagValue
+	}
+	flagCfg, err := config.FromMap(values, "flags")
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	cfg, err := config.Discover(config.Sources{File: configPath, Environ: os.Environ(), Flags: flagCfg})
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	return cfg.RegistryFile
+}
+
+// openRegistry loads the registry at path, or at the default location when
+// path is empty.
+func openRegistry(path string) *registry.Registry {
+	if path == "" {
+		var err error
+		if path, err = registry.DefaultPath(); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	reg, err := registry.Load(path)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return reg
+}
+
+// projectKey resolves a project directory to its absolute path and git remote.
+func projectKey(arg string) (string, string) {
+	path, err := filepath.Abs(arg)
+	if err != nil {
+		log.Fatalf("resolve path: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		log.Fatalf("%s is not a directory", path)
+	}
+	return path, analyze.GitRemote(path)
+}
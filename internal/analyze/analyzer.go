@@ -40,14 +40,50 @@ func mergeMetrics(a, b model.ProjectMetrics) model.ProjectMetrics {
 	result.HasTests = result.HasTests || b.HasTests
 	result.HasCI = result.HasCI || b.HasCI
 	result.HasDocker = result.HasDocker || b.HasDocker
+	result.HasLicense = result.HasLicense || b.HasLicense
+	if result.License == "" {
+		result.License = b.License
+	}
 
 	result.CommitCount = maxInt(result.CommitCount, b.CommitCount)
 	result.ActiveDays = maxInt(result.ActiveDays, b.ActiveDays)
 	result.Files = maxInt(result.Files, b.Files)
 	result.LinesOfCode = maxInt(result.LinesOfCode, b.LinesOfCode)
+	if b.SourceBytes > result.SourceBytes {
+		result.SourceBytes = b.SourceBytes
+	}
+	if b.ArtifactBytes > result.ArtifactBytes {
+		result.ArtifactBytes = b.ArtifactBytes
+		result.Artifacts = b.Artifacts
+	}
+	result.TestFiles = maxInt(result.TestFiles, b.TestFiles)
+	result.TestLinesOfCode = maxInt(result.TestLinesOfCode, b.TestLinesOfCode)
+	if b.TestRatio > result.TestRatio {
+		result.TestRatio = b.TestRatio
+	}
 
-	result.Languages = mergeLanguages(result.Languages, b.Languages)
+	result.Dependencies = maxInt(result.Dependencies, b.Dependencies)
+	result.DevDependencies = maxInt(result.DevDependencies, b.DevDependencies)
+	if len(result.Manifests) == 0 {
+		result.Manifests = b.Manifests
+	}
+	if len(result.Toolchains) == 0 {
+		result.Toolchains = b.Toolchains
+	}
+	result.PinnedDependencies = maxInt(result.PinnedDependencies, b.PinnedDependencies)
+	result.Stale = append(result.Stale, b.Stale...)
+	result.IsStale = result.IsStale || b.IsStale
+
+	result.Languages = mergeUnique(result.Languages, b.Languages)
+	result.CISystems = mergeUnique(result.CISystems, b.CISystems)
+	result.Frameworks = mergeUnique(result.Frameworks, b.Frameworks)
+	if result.ProjectType == "" {
+		result.ProjectType = b.ProjectType
+	}
 
+	if result.Remote == "" {
+		result.Remote = b.Remote
+	}
 	if b.LastTouched.After(result.LastTouched) {
 		result.LastTouched = b.LastTouched
 	}
@@ -55,23 +91,23 @@ func mergeMetrics(a, b model.ProjectMetrics) model.ProjectMetrics {
 	return result
 }
 
-func mergeLanguages(existing, incoming []string) []string {
+func mergeUnique(existing, incoming []string) []string {
 	if len(existing) == 0 && len(incoming) == 0 {
 		return nil
 	}
 
 	set := make(map[string]struct{}, len(existing)+len(incoming))
-	for _, lang := range existing {
-		if lang == "" {
+	for _, item := range existing {
+		if item == "" {
 			continue
 		}
-		set[lang] = struct{}{}
+		set[item] = struct{}{}
 	}
-	for _, lang := range incoming {
-		if lang == "" {
+	for _, item := range incoming {
+		if item == "" {
 			continue
 		}
-		set[lang] = struct{}{}
+		set[item] = struct{}{}
 	}
 
 	if len(set) == 0 {
@@ -79,8 +115,8 @@ func mergeLanguages(existing, incoming []string) []string {
 	}
 
 	out := make([]string, 0, len(set))
-	for lang := range set {
-		out = append(out, lang)
+	for item := range set {
+		out = append(out, item)
 	}
 	sort.Strings(out)
 	return out
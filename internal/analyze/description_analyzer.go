@@ -0,0 +1,162 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxDescriptionLength = 120
+
+// Describer produces a one-line, human-readable description of a project.
+type Describer interface {
+	Describe(path string) (string, error)
+}
+
+type DescriptionAnalyzer struct{}
+
+func NewDescriptionAnalyzer() *DescriptionAnalyzer {
+	return &DescriptionAnalyzer{}
+}
+
+// Describe prefers a description declared in a manifest, then the first
+// paragraph of the README, and finally the go.mod module path.
+func (d *DescriptionAnalyzer) Describe(path string) (string, error) {
+	manifests := readManifests(path)
+	for _, m := range manifests {
+		if m.info.Description != "" {
+			return truncateDescription(m.info.Description), nil
+		}
+	}
+
+	if readme := findReadme(path); readme != "" {
+		data, err := os.ReadFile(readme)
+		if err == nil {
+			if paragraph := firstParagraph(string(data)); paragraph != "" {
+				return truncateDescription(paragraph), nil
+			}
+		}
+	}
+
+	for _, m := range manifests {
+		if m.info.File == "go.mod" && m.info.Name != "" {
+			return m.info.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func findReadme(root string) string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	var candidates []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(strings.ToLower(entry.Name()), "readme") {
+			candidates = append(candidates, entry.Name())
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	// Prefer README.md over README.txt and friends when several exist.
+	sort.Slice(candidates, func(i, j int) bool {
+		mi := strings.HasSuffix(strings.ToLower(candidates[i]), ".md")
+		mj := strings.HasSuffix(strings.ToLower(candidates[j]), ".md")
+		if mi != mj {
+			return mi
+		}
+		return candidates[i] < candidates[j]
+	})
+	return filepath.Join(root, candidates[0])
+}
+
+var (
+	markdownImage    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownEmphasis = regexp.MustCompile("[*_`]+")
+)
+
+// firstParagraph returns the first prose paragraph of a README, skipping
+// headings, badges, HTML and code blocks.
+func firstParagraph(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var paragraph []string
+	inFence := false
+	inComment := false
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			inFence = !inFence
+			continue
+		case inFence:
+			continue
+		case strings.HasPrefix(line, "<!--"):
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case inComment:
+			inComment = !strings.Contains(line, "-->")
+			continue
+		}
+
+		if line == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if isUnderline(line) {
+			// A setext heading: the collected line was its title.
+			paragraph = nil
+			continue
+		}
+		if i+1 < len(lines) && isUnderline(strings.TrimSpace(lines[i+1])) {
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<") ||
+			strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![") ||
+			strings.HasPrefix(line, "|") || strings.HasPrefix(line, ">") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+
+	out := strings.Join(paragraph, " ")
+	out = markdownImage.ReplaceAllString(out, "")
+	out = markdownLink.ReplaceAllString(out, "$1")
+	out = markdownEmphasis.ReplaceAllString(out, "")
+	return strings.Join(strings.Fields(out), " ")
+}
+
+func isUnderline(line string) bool {
+	if len(line) < 3 {
+		return false
+	}
+	return strings.Trim(line, "=") == "" || strings.Trim(line, "-") == ""
+}
+
+func truncateDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= maxDescriptionLength {
+		return text
+	}
+	cut := strings.LastIndex(text[:maxDescriptionLength], " ")
+	if cut <= 0 {
+		// Without a space to break at, cut on a rune boundary so text such
+		// as CJK stays valid UTF-8.
+		cut = maxDescriptionLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimRight(text[:cut], ",;:") + "..."
+}
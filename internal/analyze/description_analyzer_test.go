@@ -0,0 +1,78 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFirstParagraph(t *testing.T) {
+	readme := `<!-- generated -->
+# scratch
+
+[![CI](https://example.com/badge.svg)](https://example.com)
+
+A **tiny** tool for [tracking](https://example.com) things.
+Second line.
+
+More text.
+`
+	want := "A tiny tool for tracking things. Second line."
+	if got := firstParagraph(readme); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	setext := "Title\n=====\n\nBody text here.\n"
+	if got := firstParagraph(setext); got != "Body text here." {
+		t.Fatalf("unexpected setext paragraph %q", got)
+	}
+}
+
+func TestDescriptionAnalyzerPrecedence(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	describer := NewDescriptionAnalyzer()
+	describe := func() string {
+		t.Helper()
+		got, err := describer.Describe(root)
+		if err != nil {
+			t.Fatalf("Describe returned error: %v", err)
+		}
+		return got
+	}
+
+	write("go.mod", "module example.com/test2\n")
+	if got := describe(); got != "example.com/test2" {
+		t.Fatalf("expected module path fallback, got %q", got)
+	}
+
+	write("README.md", "# test2\n\nExperiments with event sourcing.\n")
+	if got := describe(); got != "Experiments with event sourcing." {
+		t.Fatalf("expected README paragraph, got %q", got)
+	}
+
+	write("package.json", `{"name": "test2", "description": "Dashboard for sensor data"}`)
+	if got := describe(); got != "Dashboard for sensor data" {
+		t.Fatalf("expected manifest description, got %q", got)
+	}
+}
+
+func TestTruncateDescriptionKeepsRunesWhole(t *testing.T) {
+	// 1 + 60*3 bytes with no space: byte 120 falls inside a rune.
+	text := "a" + strings.Repeat("漢", 60)
+	got := truncateDescription(text)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncated description is not valid UTF-8: %q", got)
+	}
+	if want := "a" + strings.Repeat("漢", 39) + "..."; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,249 @@
+package analyze
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// importScanLimit caps how much of each source file is searched for imports.
+const importScanLimit = 32 * 1024
+
+// FrameworkRule identifies a framework. A project uses the framework when it
+// declares one of Dependencies (a trailing "*" matches a prefix), contains a
+// file matching one of the Files globs, or has a source file with one of the
+// Extensions containing one of the Imports.
+type FrameworkRule struct {
+	Type         string
+	Dependencies []string
+	Files        []string
+	Imports      []string
+	Extensions   []string
+}
+
+// projectTypePriority decides the project type when frameworks disagree: a
+// web app with a CLI entrypoint or Terraform alongside is still a web app.
+var projectTypePriority = []string{"web", "cli", "infra", "library"}
+
+type FrameworkAnalyzer struct {
+	ignoreDirs    map[string]struct{}
+	includeHidden bool
+	rules         map[string]FrameworkRule
+}
+
+func NewFrameworkAnalyzer(ignoreDirs []string, includeHidden bool, rules map[string]FrameworkRule) *FrameworkAnalyzer {
+	return &FrameworkAnalyzer{
+		ignoreDirs:    makeIgnoreSet(ignoreDirs),
+		includeHidden: includeHidden,
+		rules:         rules,
+	}
+}
+
+func (a *FrameworkAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
+	found := make(map[string]struct{})
+
+	deps := dependencyNames(readManifests(path))
+	for name, rule := range a.rules {
+		if matchesDependency(deps, rule.Dependencies) {
+			found[name] = struct{}{}
+		}
+	}
+
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			if p != path && a.shouldSkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		var content string
+		for name, rule := range a.rules {
+			if _, ok := found[name]; ok {
+				continue
+			}
+			if matchesAnyGlob(d.Name(), rule.Files) {
+				found[name] = struct{}{}
+				continue
+			}
+			if len(rule.Imports) == 0 || !containsFold(rule.Extensions, ext) {
+				continue
+			}
+			if content == "" {
+				content = readHead(p, importScanLimit)
+			}
+			if containsImport(content, rule.Imports) {
+				found[name] = struct{}{}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.ProjectMetrics{}, err
+	}
+
+	var metrics model.ProjectMetrics
+	types := make(map[string]struct{})
+	for name := range found {
+		metrics.Frameworks = append(metrics.Frameworks, name)
+		if t := a.rules[name].Type; t != "" {
+			types[t] = struct{}{}
+		}
+	}
+	sort.Strings(metrics.Frameworks)
+
+	metrics.ProjectType = pickProjectType(types)
+	if metrics.ProjectType == "" {
+		metrics.ProjectType = guessProjectType(path)
+	}
+	return metrics, nil
+}
+
+func (a *FrameworkAnalyzer) shouldSkipDir(name string) bool {
+	if name == "" {
+		return false
+	}
+	if !a.includeHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	_, skip := a.ignoreDirs[name]
+	return skip
+}
+
+func matchesDependency(deps []string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		for _, dep := range deps {
+			dep = strings.ToLower(dep)
+			if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+				if strings.HasPrefix(dep, prefix) {
+					return true
+				}
+			} else if dep == pattern {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAnyGlob(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// importKeywords start the lines that can import code across the supported
+// languages. Matching only these lines keeps string literals elsewhere (for
+// example in tests) from counting as usage.
+var importKeywords = []string{"import", "from", "require", "use ", "using ", "#include", "extern crate"}
+
+func containsImport(content string, patterns []string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !containsAny(line, patterns) {
+			continue
+		}
+		for _, keyword := range importKeywords {
+			if strings.HasPrefix(line, keyword) {
+				return true
+			}
+		}
+		// Entries inside a Go import block: a path with an optional alias.
+		if fields := strings.Fields(line); len(fields) <= 2 && strings.HasSuffix(line, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+func readHead(path string, limit int64) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func pickProjectType(types map[string]struct{}) string {
+	for _, t := range projectTypePriority {
+		if _, ok := types[t]; ok {
+			return t
+		}
+	}
+	// Custom types from user rules have no priority; pick deterministically.
+	custom := sortedKeys(types)
+	if len(custom) > 0 {
+		return custom[0]
+	}
+	return ""
+}
+
+// guessProjectType classifies projects without a recognized framework from
+// the shape of their entrypoints.
+func guessProjectType(root string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(root, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("Cargo.toml") && exists(filepath.Join("src", "main.rs")):
+		return "cli"
+	case exists("Cargo.toml") && exists(filepath.Join("src", "lib.rs")):
+		return "library"
+	case exists("go.mod") && (exists("cmd") || exists("main.go")):
+		return "cli"
+	case exists("go.mod"):
+		return "library"
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			Bin  json.RawMessage `json:"bin"`
+			Main string          `json:"main"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			if len(pkg.Bin) > 0 {
+				return "cli"
+			}
+			if pkg.Main != "" {
+				return "library"
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "pyproject.toml")); err == nil {
+		if len(parseTOML(data).table("project.scripts")) > 0 {
+			return "cli"
+		}
+		return "library"
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFrameworkAnalyzer(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/svc\n\nrequire github.com/spf13/cobra v1.8.0\n",
+		"server/http.go": "package server\n\nimport \"github.com/gin-gonic/gin\"\n",
+		"deploy/main.tf": "resource \"null_resource\" \"x\" {}\n",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	rules := map[string]FrameworkRule{
+		"Cobra":     {Type: "cli", Dependencies: []string{"github.com/spf13/cobra"}},
+		"Gin":       {Type: "web", Imports: []string{`"github.com/gin-gonic/gin"`}, Extensions: []string{".go"}},
+		"Terraform": {Type: "infra", Files: []string{"*.tf"}},
+		"Spring":    {Type: "web", Dependencies: []string{"org.springframework:*"}},
+	}
+	metrics, err := NewFrameworkAnalyzer(nil, false, rules).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if want := []string{"Cobra", "Gin", "Terraform"}; !reflect.DeepEqual(metrics.Frameworks, want) {
+		t.Fatalf("expected frameworks %v, got %v", want, metrics.Frameworks)
+	}
+	if metrics.ProjectType != "web" {
+		t.Fatalf("expected web project type, got %q", metrics.ProjectType)
+	}
+}
+
+func TestFrameworkAnalyzerGuessesType(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	for _, name := range []string{"Cargo.toml", filepath.Join("src", "lib.rs")} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(""), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	metrics, err := NewFrameworkAnalyzer(nil, false, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if metrics.ProjectType != "library" {
+		t.Fatalf("expected library project type, got %q", metrics.ProjectType)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/ErikOlson/proj-audit/internal/model"
@@ -15,17 +16,33 @@ import (
 type FsAnalyzer struct {
 	ignoreDirs    map[string]struct{}
 	includeHidden bool
+	ciMarkers     map[string][]string
+	artifactDirs  map[string]struct{}
+	testRules     map[string]TestRule
 }
 
-func NewFsAnalyzer(ignoreDirs []string, includeHidden bool) *FsAnalyzer {
+// NewFsAnalyzer builds an analyzer that walks project files. ciMarkers maps a
+// CI system name to paths (relative to the project root, globs allowed) whose
+// presence indicates that system is configured. Directories named in
+// artifactDirs are not walked; their size is reported as reclaimable when they
+// sit at the project root or, deeper down, when git tracks nothing in them, so
+// a committed src/build is never mistaken for output. HasTests is set when a
+// file matches the file or directory patterns of any of testRules, whatever
+// its language, so tests are found even with the lang analyzer disabled.
+func NewFsAnalyzer(ignoreDirs []string, includeHidden bool, ciMarkers map[string][]string, artifactDirs []string, testRules map[string]TestRule) *FsAnalyzer {
 	return &FsAnalyzer{
 		ignoreDirs:    makeIgnoreSet(ignoreDirs),
 		includeHidden: includeHidden,
+		ciMarkers:     ciMarkers,
+		artifactDirs:  makeIgnoreSet(artifactDirs),
+		testRules:     testRules,
 	}
 }
 
 func (f *FsAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 	var metrics model.ProjectMetrics
+	var licenses []string
+	inGit, _ := insideWorkTree(path)
 
 	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
@@ -33,14 +50,24 @@ func (f *FsAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 		}
 
 		if d.IsDir() {
-			if p != path && f.shouldSkipDir(d.Name()) {
-				return filepath.SkipDir
+			if p == path {
+				return nil
 			}
-			// Detect CI configurations eagerly to avoid additional passes.
-			if d.Name() == ".github" {
-				if info, err := os.Stat(filepath.Join(p, "workflows")); err == nil && info.IsDir() {
-					metrics.HasCI = true
+			if _, ok := f.artifactDirs[d.Name()]; ok {
+				if filepath.Dir(p) != path && !(inGit && untracked(p)) {
+					return filepath.SkipDir
+				}
+				size, err := dirSize(p)
+				if err != nil {
+					return err
 				}
+				rel, _ := filepath.Rel(path, p)
+				metrics.Artifacts = append(metrics.Artifacts, model.Artifact{Path: filepath.ToSlash(rel), Bytes: size})
+				metrics.ArtifactBytes += size
+				return filepath.SkipDir
+			}
+			if f.shouldSkipDir(d.Name()) {
+				return filepath.SkipDir
 			}
 			return nil
 		}
@@ -58,7 +85,14 @@ func (f *FsAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 			metrics.HasREADME = true
 		}
 
-		if hasTestIndicator(lowerName, p) {
+		if filepath.Dir(p) == path && isLicenseFile(lowerName) {
+			metrics.HasLicense = true
+			if data, err := os.ReadFile(p); err == nil {
+				licenses = append(licenses, identifyLicense(string(data)))
+			}
+		}
+
+		if !metrics.HasTests && f.isTestFile(path, p) {
 			metrics.HasTests = true
 		}
 
@@ -66,11 +100,8 @@ func (f *FsAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 			metrics.HasDocker = true
 		}
 
-		if lowerName == ".gitlab-ci.yml" {
-			metrics.HasCI = true
-		}
-
 		if info, err := d.Info(); err == nil {
+			metrics.SourceBytes += info.Size()
 			modTime := info.ModTime()
 			if modTime.After(metrics.LastTouched) {
 				metrics.LastTouched = modTime
@@ -83,9 +114,27 @@ func (f *FsAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 		return model.ProjectMetrics{}, fmt.Errorf("fs analyzer walk: %w", err)
 	}
 
+	metrics.CISystems = f.detectCI(path)
+	metrics.HasCI = len(metrics.CISystems) > 0
+
+	// A license declared in a manifest is an explicit SPDX expression, so it
+	// takes precedence over identifying the license file by its text.
+	if declared := manifestLicense(path); declared != "" {
+		metrics.HasLicense = true
+		metrics.License = declared
+	} else if len(licenses) > 0 {
+		metrics.License = combineLicenses(licenses)
+	}
+
 	return metrics, nil
 }
 
+// untracked reports whether git tracks no file beneath dir.
+func untracked(dir string) bool {
+	tracked, err := GitTracked(dir)
+	return err == nil && !tracked
+}
+
 func (f *FsAnalyzer) shouldSkipDir(name string) bool {
 	if name == "" {
 		return false
@@ -97,6 +146,57 @@ func (f *FsAnalyzer) shouldSkipDir(name string) bool {
 	return skip
 }
 
+func (f *FsAnalyzer) detectCI(root string) []string {
+	var systems []string
+	for system, markers := range f.ciMarkers {
+		for _, marker := range markers {
+			matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(marker)))
+			if err == nil && len(matches) > 0 {
+				systems = append(systems, system)
+				break
+			}
+		}
+	}
+	sort.Strings(systems)
+	return systems
+}
+
+// dirSize returns the total size of the regular files beneath path.
+func dirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			// Unreadable entries inside artifact directories are not fatal.
+			if d != nil && d.IsDir() && p != path {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// isTestFile reports whether the file p under the project root matches any
+// language's test file or directory patterns.
+func (f *FsAnalyzer) isTestFile(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	for _, rule := range f.testRules {
+		if matchesTestFile(rel, rule) {
+			return true
+		}
+	}
+	return false
+}
+
 func countLines(path string) (int, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -117,22 +217,3 @@ func countLines(path string) (int, error) {
 	}
 	return count, nil
 }
-
-func hasTestIndicator(lowerName, fullPath string) bool {
-	if strings.HasSuffix(lowerName, "_test.go") {
-		return true
-	}
-
-	jsTestSuffixes := []string{".spec.js", ".test.js", ".spec.ts", ".test.ts"}
-	for _, suffix := range jsTestSuffixes {
-		if strings.HasSuffix(lowerName, suffix) {
-			return true
-		}
-	}
-
-	if strings.Contains(fullPath, string(os.PathSeparator)+"__tests__"+string(os.PathSeparator)) {
-		return true
-	}
-
-	return false
-}
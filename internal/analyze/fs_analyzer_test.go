@@ -0,0 +1,154 @@
+package analyze
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestFsAnalyzerDetectsCISystems(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".circleci"), 0o755); err != nil {
+		t.Fatalf("create .circleci: %v", err)
+	}
+	for _, name := range []string{".circleci/config.yml", "Jenkinsfile"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	markers := map[string][]string{
+		"CircleCI":       {".circleci/config.yml"},
+		"Jenkins":        {"Jenkinsfile"},
+		"GitHub Actions": {".github/workflows"},
+	}
+	metrics, err := NewFsAnalyzer(nil, false, markers, nil, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if !metrics.HasCI {
+		t.Fatalf("expected CI to be detected")
+	}
+	if want := []string{"CircleCI", "Jenkins"}; !reflect.DeepEqual(metrics.CISystems, want) {
+		t.Fatalf("expected %v, got %v", want, metrics.CISystems)
+	}
+}
+
+func TestFsAnalyzerMeasuresArtifacts(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{
+		"main.go":                   10,
+		"node_modules/pkg/index.js": 100,
+		"web/.venv/lib/site.py":     40,
+		"web/__pycache__/mod.pyc":   5,
+		"web/app.py":                20,
+	}
+	for name, size := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	artifacts := []string{"node_modules", ".venv", "__pycache__"}
+	metrics, err := NewFsAnalyzer(artifacts, false, nil, artifacts, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if metrics.SourceBytes != 30 {
+		t.Fatalf("expected 30 source bytes, got %d", metrics.SourceBytes)
+	}
+	// Outside git only the root-level directory counts; nothing shows that
+	// the nested ones are generated.
+	if metrics.ArtifactBytes != 100 {
+		t.Fatalf("expected 100 artifact bytes, got %d", metrics.ArtifactBytes)
+	}
+	if len(metrics.Artifacts) != 1 {
+		t.Fatalf("expected 1 artifact dir, got %+v", metrics.Artifacts)
+	}
+}
+
+func TestFsAnalyzerCountsNestedArtifactsGitDoesNotTrack(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	files := map[string]int{
+		".gitignore":                    14,
+		"src/build/gen.go":              10,
+		"web/node_modules/pkg/index.js": 100,
+	}
+	for name, size := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, make([]byte, size), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}} {
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	artifacts := []string{"build", "node_modules"}
+	metrics, err := NewFsAnalyzer(artifacts, false, nil, artifacts, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	want := []model.Artifact{{Path: "web/node_modules", Bytes: 100}}
+	if !reflect.DeepEqual(metrics.Artifacts, want) {
+		t.Fatalf("expected only web/node_modules, got %+v", metrics.Artifacts)
+	}
+}
+
+func TestFsAnalyzerDetectsTestsWithLangDisabled(t *testing.T) {
+	layer, err := config.ParseYAML([]byte("analyzers: {lang: false}\n"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Merge(config.DefaultConfig(), layer)
+	toggles := cfg.EffectiveAnalyzers()
+	if toggles["lang"] || !toggles["fs"] {
+		t.Fatalf("expected only lang to be disabled, got %v", toggles)
+	}
+
+	cases := map[string]bool{
+		"web/App.test.tsx":         true,
+		"lib/__tests__/helpers.js": true,
+		"pkg/util_test.go":         true,
+		"pkg/util.go":              false,
+	}
+	for name, want := range cases {
+		root := t.TempDir()
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fs := NewFsAnalyzer(cfg.AllIgnoreDirs(), cfg.IncludeHidden, cfg.CIMarkers(), cfg.ArtifactDirs(), configTestRules(cfg))
+		metrics, err := NewCompositeAnalyzer(fs).Analyze(root)
+		if err != nil {
+			t.Fatalf("Analyze returned error: %v", err)
+		}
+		if metrics.HasTests != want {
+			t.Errorf("%s: HasTests = %v, want %v", name, metrics.HasTests, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -51,6 +52,7 @@ func (g *GitAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 	if errLast == nil {
 		metrics.LastTouched = lastCommit
 	}
+	metrics.Remote = GitRemote(path)
 
 	return metrics, nil
 }
@@ -100,3 +102,104 @@ func gitOutput(path string, args ...string) (string, error) {
 	}
 	return stdout.String(), nil
 }
+
+// GitRemote returns the URL of the origin remote of the repository rooted at
+// path, or "" when path is not a repository root or has no origin.
+func GitRemote(path string) string {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+		return ""
+	}
+	out, err := gitOutput(path, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// GitDirty reports whether the work tree containing path has uncommitted or
+// untracked (non-ignored) changes beneath path. Paths outside a repository are
+// never dirty.
+func GitDirty(path string) (bool, error) {
+	inside, err := insideWorkTree(path)
+	if err != nil || !inside {
+		return false, err
+	}
+	out, err := gitOutput(path, "status", "--porcelain", "--", ".")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// GitDirtyTree runs GitDirty on path and on every repository nested beneath
+// it, returning the first dirty one, or "" when all of them are clean. On an
+// error it returns the repository git failed in.
+func GitDirtyTree(path string) (string, error) {
+	if dirty, err := GitDirty(path); err != nil || dirty {
+		return path, err
+	}
+	var found string
+	err := filepath.WalkDir(path, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() != ".git" {
+			return nil
+		}
+		// A .git directory or, for submodules and worktrees, file marks a
+		// repository; its own contents are never walked.
+		if repo := filepath.Dir(current); repo != path {
+			dirty, err := GitDirty(repo)
+			if err != nil || dirty {
+				found = repo
+				if err != nil {
+					return err
+				}
+				return filepath.SkipAll
+			}
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		if found == "" {
+			found = path
+		}
+		return found, err
+	}
+	return found, nil
+}
+
+// GitTracked reports whether any file beneath path is tracked by git.
+func GitTracked(path string) (bool, error) {
+	inside, err := insideWorkTree(path)
+	if err != nil || !inside {
+		return false, err
+	}
+	out, err := gitOutput(path, "ls-files", "--", ".")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// GitWorkTree reports whether path is inside a git work tree.
+func GitWorkTree(path string) (bool, error) {
+	return insideWorkTree(path)
+}
+
+// insideWorkTree asks git whether path belongs to a work tree. When git cannot
+// answer for a directory that has its own .git, the error is returned rather
+// than guessing, so callers never treat an unreadable repository as clean.
+func insideWorkTree(path string) (bool, error) {
+	out, err := gitOutput(path, "rev-parse", "--is-inside-work-tree")
+	if err == nil {
+		return strings.TrimSpace(out) == "true", nil
+	}
+	if _, statErr := os.Stat(filepath.Join(path, ".git")); statErr == nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,36 @@
+package analyze
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitDirtyTreeChecksNestedRepositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	nested := filepath.Join(root, "tools", "gen")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "-C", nested, "init", "-q")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	repo, err := GitDirtyTree(root)
+	if err != nil || repo != "" {
+		t.Fatalf("expected a clean tree, got %q, %v", repo, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(nested, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err = GitDirtyTree(root)
+	if err != nil || repo != nested {
+		t.Fatalf("expected %s to be reported dirty, got %q, %v", nested, repo, err)
+	}
+}
@@ -1,7 +1,10 @@
 package analyze
 
 import (
+	"bufio"
 	"io/fs"
+	"math"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -9,13 +12,24 @@ import (
 	"github.com/ErikOlson/proj-audit/internal/model"
 )
 
+// TestRule describes how test code is recognized for a language. Files and
+// Dirs are glob patterns matched against file names and against the
+// directories containing a file; Markers are source lines (such as Rust's
+// #[cfg(test)]) after which the rest of the file is test code.
+type TestRule struct {
+	Files   []string
+	Dirs    []string
+	Markers []string
+}
+
 type LangAnalyzer struct {
 	ignoreDirs    map[string]struct{}
 	includeHidden bool
 	extToLang     map[string]string
+	testRules     map[string]TestRule
 }
 
-func NewLangAnalyzer(ignoreDirs []string, includeHidden bool, extMap map[string]string) *LangAnalyzer {
+func NewLangAnalyzer(ignoreDirs []string, includeHidden bool, extMap map[string]string, testRules map[string]TestRule) *LangAnalyzer {
 	mapping := normalizeExtensionMap(extMap)
 	if len(mapping) == 0 {
 		mapping = defaultExtensionMap()
@@ -24,11 +38,14 @@ func NewLangAnalyzer(ignoreDirs []string, includeHidden bool, extMap map[string]
 		ignoreDirs:    makeIgnoreSet(ignoreDirs),
 		includeHidden: includeHidden,
 		extToLang:     mapping,
+		testRules:     testRules,
 	}
 }
 
 func (l *LangAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 	languages := make(map[string]struct{})
+	var result model.ProjectMetrics
+	sourceLines := 0
 
 	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
@@ -41,9 +58,26 @@ func (l *LangAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 			return nil
 		}
 
-		if lang := l.lookupLanguage(d.Name()); lang != "" {
-			languages[lang] = struct{}{}
+		lang := l.lookupLanguage(d.Name())
+		if lang == "" {
+			return nil
+		}
+		languages[lang] = struct{}{}
+
+		rule := l.testRules[lang]
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			rel = d.Name()
 		}
+		total, testLines, err := countTestLines(p, rel, rule)
+		if err != nil {
+			return nil
+		}
+		if testLines > 0 {
+			result.TestFiles++
+			result.TestLinesOfCode += testLines
+		}
+		sourceLines += total - testLines
 		return nil
 	})
 	if err != nil {
@@ -54,17 +88,92 @@ func (l *LangAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
 		return model.ProjectMetrics{}, nil
 	}
 
-	result := model.ProjectMetrics{
-		Languages: make([]string, 0, len(languages)),
-	}
+	result.Languages = make([]string, 0, len(languages))
 	for lang := range languages {
 		result.Languages = append(result.Languages, lang)
 	}
 	sort.Strings(result.Languages)
 
+	result.HasTests = result.TestFiles > 0
+	if sourceLines > 0 {
+		result.TestRatio = math.Round(float64(result.TestLinesOfCode)/float64(sourceLines)*1000) / 1000
+	}
+
 	return result, nil
 }
 
+// countTestLines returns the total line count of a source file and how many
+// of those lines are test code according to rule. rel is the file path
+// relative to the project root.
+func countTestLines(path, rel string, rule TestRule) (int, int, error) {
+	wholeFile := matchesTestFile(rel, rule)
+	if wholeFile || len(rule.Markers) == 0 {
+		total, err := countLines(path)
+		if err != nil {
+			return 0, 0, err
+		}
+		if wholeFile {
+			return total, total, nil
+		}
+		return total, 0, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	reader.Buffer(buf, 1024*1024)
+
+	total, markerLine := 0, 0
+	for reader.Scan() {
+		total++
+		if markerLine == 0 && containsAny(reader.Text(), rule.Markers) {
+			markerLine = total
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return 0, 0, err
+	}
+	if markerLine == 0 {
+		return total, 0, nil
+	}
+	return total, total - markerLine + 1, nil
+}
+
+func matchesTestFile(rel string, rule TestRule) bool {
+	name := filepath.Base(rel)
+	for _, pattern := range rule.Files {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	dir := filepath.Dir(rel)
+	if dir == "." || len(rule.Dirs) == 0 {
+		return false
+	}
+	for _, part := range strings.Split(dir, string(filepath.Separator)) {
+		for _, pattern := range rule.Dirs {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAny(line string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *LangAnalyzer) shouldSkipDir(name string) bool {
 	if name == "" {
 		return false
@@ -108,7 +217,9 @@ func defaultExtensionMap() map[string]string {
 		".rs":   "Rust",
 		".py":   "Python",
 		".js":   "JavaScript",
+		".jsx":  "JavaScript",
 		".ts":   "TypeScript",
+		".tsx":  "TypeScript",
 		".java": "Java",
 		".cs":   "C#",
 		".c":    "C/C++",
@@ -0,0 +1,84 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/config"
+)
+
+func TestLangAnalyzerCountsTests(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app/main.py":         "import os\nprint(os.name)\n",
+		"app/test_main.py":    "def test_it():\n    assert True\n",
+		"tests/helpers.py":    "X = 1\n",
+		"src/lib.rs":          "pub fn add() {}\n\n#[cfg(test)]\nmod tests {\n}\n",
+		"src/test/FooIT.java": "class FooIT {}\n",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	rules := map[string]TestRule{
+		"Python": {Files: []string{"test_*.py"}, Dirs: []string{"tests"}},
+		"Rust":   {Markers: []string{"#[cfg(test)]"}},
+	}
+	metrics, err := NewLangAnalyzer(nil, false, nil, rules).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	if !metrics.HasTests {
+		t.Fatalf("expected tests to be detected")
+	}
+	// test_main.py (2 lines), tests/helpers.py (1 line), lib.rs from the marker (3 lines).
+	if metrics.TestFiles != 3 {
+		t.Fatalf("expected 3 test files, got %d", metrics.TestFiles)
+	}
+	if metrics.TestLinesOfCode != 6 {
+		t.Fatalf("expected 6 test lines, got %d", metrics.TestLinesOfCode)
+	}
+	// Source lines: main.py (2), lib.rs before the marker (2), FooIT.java (1).
+	if metrics.TestRatio != 1.2 {
+		t.Fatalf("expected test ratio 1.2, got %v", metrics.TestRatio)
+	}
+}
+
+func TestLangAnalyzerDefaultsRecognizeJSXTests(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"App.tsx", "App.test.tsx", "Nav.spec.tsx", "Button.jsx", "Button.test.jsx", "Menu.spec.jsx"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("export {}\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	defaults := config.DefaultConfig()
+	metrics, err := NewLangAnalyzer(nil, false, defaults.ExtensionMapping(), configTestRules(defaults)).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if metrics.TestFiles != 4 {
+		t.Fatalf("expected 4 test files, got %d", metrics.TestFiles)
+	}
+	if len(metrics.Languages) != 2 || metrics.Languages[0] != "JavaScript" || metrics.Languages[1] != "TypeScript" {
+		t.Fatalf("expected JavaScript and TypeScript, got %v", metrics.Languages)
+	}
+}
+
+// configTestRules builds the test rules of every language in cfg, as the
+// audit command does.
+func configTestRules(cfg config.Config) map[string]TestRule {
+	rules := make(map[string]TestRule, len(cfg.Languages))
+	for name, lang := range cfg.Languages {
+		rules[name] = TestRule{Files: lang.TestFiles, Dirs: lang.TestDirs, Markers: lang.TestMarkers}
+	}
+	return rules
+}
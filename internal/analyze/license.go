@@ -0,0 +1,181 @@
+package analyze
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const unknownLicense = "Unknown"
+
+type licenseSignature struct {
+	id string
+	// title phrases must appear near the top of the text; the GPL family
+	// mention each other in their bodies, so only the heading is reliable.
+	title []string
+	// body phrases may appear anywhere in the text.
+	body []string
+}
+
+// licenseSignatures are checked in order; more specific texts come first.
+var licenseSignatures = []licenseSignature{
+	{id: "AGPL-3.0", title: []string{"gnu affero general public license", "version 3"}},
+	{id: "LGPL-3.0", title: []string{"gnu lesser general public license", "version 3"}},
+	{id: "LGPL-2.1", title: []string{"gnu lesser general public license", "version 2 1"}},
+	{id: "GPL-3.0", title: []string{"gnu general public license", "version 3"}},
+	{id: "GPL-2.0", title: []string{"gnu general public license", "version 2"}},
+	{id: "Apache-2.0", title: []string{"apache license", "version 2 0"}},
+	{id: "MPL-2.0", title: []string{"mozilla public license", "2 0"}},
+	{id: "EPL-2.0", title: []string{"eclipse public license", "v 2 0"}},
+	{id: "BSL-1.0", title: []string{"boost software license", "version 1 0"}},
+	{id: "CC0-1.0", body: []string{"cc0 1 0 universal"}},
+	{id: "Unlicense", body: []string{"this is free and unencumbered software released into the public domain"}},
+	{id: "BSD-4-Clause", body: []string{"redistribution and use in source and binary forms", "all advertising materials mentioning features"}},
+	{id: "BSD-3-Clause", body: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-2-Clause", body: []string{"redistribution and use in source and binary forms"}},
+	{id: "MIT", body: []string{"permission is hereby granted free of charge", "the above copyright notice and this permission notice shall be included"}},
+	{id: "ISC", body: []string{"permission to use copy modify and or distribute this software for any purpose"}},
+	{id: "Zlib", body: []string{"this software is provided as is without any express or implied warranty", "altered source versions must be plainly marked"}},
+}
+
+// licenseTitleWindow is how much of the normalized text is searched for titles.
+const licenseTitleWindow = 1000
+
+func isLicenseFile(lowerName string) bool {
+	base := strings.TrimSuffix(lowerName, filepath.Ext(lowerName))
+	for _, prefix := range []string{"license", "licence", "copying", "unlicense"} {
+		if base == prefix || strings.HasPrefix(base, prefix+"-") || strings.HasPrefix(base, prefix+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+// identifyLicense returns the SPDX identifier matching the license text, or
+// unknownLicense when the text is not recognized.
+func identifyLicense(text string) string {
+	if id := spdxIdentifierTag(text); id != "" {
+		return id
+	}
+
+	normalized := normalizeLicenseText(text)
+	head := normalized
+	if len(head) > licenseTitleWindow {
+		head = head[:licenseTitleWindow]
+	}
+
+	for _, sig := range licenseSignatures {
+		if containsAll(head, sig.title) && containsAll(normalized, sig.body) {
+			return sig.id
+		}
+	}
+	return unknownLicense
+}
+
+func spdxIdentifierTag(text string) string {
+	const tag = "SPDX-License-Identifier:"
+	idx := strings.Index(text, tag)
+	if idx == -1 {
+		return ""
+	}
+	rest := text[idx+len(tag):]
+	if nl := strings.IndexAny(rest, "\r\n"); nl != -1 {
+		rest = rest[:nl]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), "*/"))
+}
+
+// normalizeLicenseText lowercases the text and collapses punctuation and
+// whitespace so that reflowed or comment-wrapped copies still match.
+func normalizeLicenseText(text string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func containsAll(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if !strings.Contains(text, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+// combineLicenses joins the licenses found in separate files (e.g. the
+// LICENSE-MIT/LICENSE-APACHE pair common in Rust crates) into one expression.
+func combineLicenses(ids []string) string {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	if len(set) > 1 {
+		delete(set, unknownLicense)
+	}
+	out := make([]string, 0, len(set))
+	for id := range set {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return strings.Join(out, " OR ")
+}
+
+// manifestLicense returns the license declared in package.json, Cargo.toml or
+// pyproject.toml at the project root.
+func manifestLicense(root string) string {
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			License json.RawMessage `json:"license"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && len(pkg.License) > 0 {
+			var id string
+			if json.Unmarshal(pkg.License, &id) == nil && id != "" {
+				return id
+			}
+			var legacy struct {
+				Type string `json:"type"`
+			}
+			if json.Unmarshal(pkg.License, &legacy) == nil && legacy.Type != "" {
+				return legacy.Type
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "Cargo.toml")); err == nil {
+		if id := parseTOML(data).str("package", "license"); id != "" {
+			return id
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "pyproject.toml")); err == nil {
+		doc := parseTOML(data)
+		switch v := doc.table("project")["license"].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case map[string]interface{}:
+			if text, ok := v["text"].(string); ok && text != "" {
+				return text
+			}
+		}
+		if id := doc.str("tool.poetry", "license"); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIdentifyLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "MIT",
+			text: `MIT License
+
+Copyright (c) 2024 Someone
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction...
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.`,
+			want: "MIT",
+		},
+		{
+			name: "Apache",
+			text: "                                 Apache License\n                           Version 2.0, January 2004\n",
+			want: "Apache-2.0",
+		},
+		{
+			name: "GPL3 mentions LGPL in body",
+			text: "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n" + padding(2000) +
+				"consider it more useful to permit linking proprietary applications with the library. " +
+				"If this is what you want to do, use the GNU Lesser General Public License instead of this License.",
+			want: "GPL-3.0",
+		},
+		{
+			name: "LGPL 2.1",
+			text: "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 2.1, February 1999",
+			want: "LGPL-2.1",
+		},
+		{
+			name: "BSD 3 clause",
+			text: "Redistribution and use in source and binary forms, with or without modification, are permitted...\n" +
+				"3. Neither the name of the copyright holder nor the names of its contributors may be used",
+			want: "BSD-3-Clause",
+		},
+		{
+			name: "SPDX tag",
+			text: "// SPDX-License-Identifier: MPL-2.0\n",
+			want: "MPL-2.0",
+		},
+		{
+			name: "unrecognized",
+			text: "All rights reserved.",
+			want: unknownLicense,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identifyLicense(tt.text); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFsAnalyzerLicense(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "LICENSE-MIT"), []byte("Permission is hereby granted, free of charge, to any person. The above copyright notice and this permission notice shall be included in all copies."), 0o644); err != nil {
+		t.Fatalf("write license: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "LICENSE-APACHE"), []byte("Apache License\nVersion 2.0, January 2004"), 0o644); err != nil {
+		t.Fatalf("write license: %v", err)
+	}
+
+	metrics, err := NewFsAnalyzer(nil, false, nil, nil, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if !metrics.HasLicense {
+		t.Fatalf("expected license to be detected")
+	}
+	if metrics.License != "Apache-2.0 OR MIT" {
+		t.Fatalf("unexpected license %q", metrics.License)
+	}
+
+	cargo := "[package]\nname = \"demo\"\nlicense = \"MIT OR Apache-2.0\" # dual\n"
+	if err := os.WriteFile(filepath.Join(root, "Cargo.toml"), []byte(cargo), 0o644); err != nil {
+		t.Fatalf("write Cargo.toml: %v", err)
+	}
+	metrics, err = NewFsAnalyzer(nil, false, nil, nil, nil).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+	if metrics.License != "MIT OR Apache-2.0" {
+		t.Fatalf("expected manifest license, got %q", metrics.License)
+	}
+}
+
+func padding(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = 'x'
+		if i%10 == 9 {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,491 @@
+package analyze
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// manifestData is a parsed manifest. The dependency maps hold the declared
+// version constraint for each dependency name.
+type manifestData struct {
+	info    model.Manifest
+	deps    map[string]string
+	devDeps map[string]string
+}
+
+type manifestParser func(data []byte) manifestData
+
+// manifestParsers lists the supported manifests in the order they are reported.
+var manifestParsers = []struct {
+	file  string
+	parse manifestParser
+}{
+	{"go.mod", parseGoMod},
+	{"package.json", parsePackageJSON},
+	{"Cargo.toml", parseCargoToml},
+	{"pyproject.toml", parsePyproject},
+	{"requirements.txt", parseRequirements},
+	{"pom.xml", parsePom},
+	{"build.gradle", parseGradle},
+	{"build.gradle.kts", parseGradle},
+}
+
+func readManifests(root string) []manifestData {
+	var out []manifestData
+	for _, mp := range manifestParsers {
+		data, err := os.ReadFile(filepath.Join(root, mp.file))
+		if err != nil {
+			continue
+		}
+		m := mp.parse(data)
+		m.info.File = mp.file
+		m.info.Dependencies = len(m.deps)
+		m.info.DevDependencies = len(m.devDeps)
+		if strings.HasPrefix(mp.file, "build.gradle") {
+			if name := gradleProjectName(root); name != "" && m.info.Name != "" {
+				m.info.Name += ":" + name
+			} else if name != "" {
+				m.info.Name = name
+			}
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// dependencyNames returns the sorted names of every dependency declared in
+// the manifests, including development dependencies.
+func dependencyNames(manifests []manifestData) []string {
+	set := make(map[string]struct{})
+	for _, m := range manifests {
+		for name := range m.deps {
+			set[name] = struct{}{}
+		}
+		for name := range m.devDeps {
+			set[name] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func parseGoMod(data []byte) manifestData {
+	m := manifestData{
+		info: model.Manifest{Ecosystem: "go"},
+		deps: make(map[string]string),
+	}
+	var goVersion, toolchain string
+	inRequire := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.Contains(line, "// indirect")
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+		if inRequire {
+			if line == ")" {
+				inRequire = false
+				continue
+			}
+			addGoRequire(m.deps, line, indirect)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "module":
+			if len(fields) > 1 {
+				m.info.Name = strings.Trim(fields[1], `"`)
+			}
+		case "go":
+			if len(fields) > 1 {
+				goVersion = fields[1]
+			}
+		case "toolchain":
+			if len(fields) > 1 {
+				toolchain = fields[1]
+			}
+		case "require":
+			if len(fields) > 1 && fields[1] == "(" {
+				inRequire = true
+				continue
+			}
+			addGoRequire(m.deps, strings.TrimSpace(strings.TrimPrefix(line, "require")), indirect)
+		}
+	}
+
+	switch {
+	case toolchain != "":
+		m.info.Toolchain = "go " + strings.TrimPrefix(toolchain, "go")
+	case goVersion != "":
+		m.info.Toolchain = "go " + goVersion
+	}
+	return m
+}
+
+func addGoRequire(deps map[string]string, line string, indirect bool) {
+	if indirect {
+		return
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return
+	}
+	deps[fields[0]] = fields[1]
+}
+
+func parsePackageJSON(data []byte) manifestData {
+	var pkg struct {
+		Name            string            `json:"name"`
+		Description     string            `json:"description"`
+		Version         string            `json:"version"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+		Engines         map[string]string `json:"engines"`
+	}
+	m := manifestData{info: model.Manifest{Ecosystem: "npm"}}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return m
+	}
+	m.info.Name = pkg.Name
+	m.info.Description = pkg.Description
+	m.info.Version = pkg.Version
+	m.deps = pkg.Dependencies
+	m.devDeps = pkg.DevDependencies
+	if node := pkg.Engines["node"]; node != "" {
+		m.info.Toolchain = "node " + node
+	}
+	return m
+}
+
+func parseCargoToml(data []byte) manifestData {
+	doc := parseTOML(data)
+	m := manifestData{
+		info: model.Manifest{
+			Ecosystem:   "cargo",
+			Name:        doc.str("package", "name"),
+			Description: doc.str("package", "description"),
+			Version:     doc.str("package", "version"),
+		},
+		deps:    make(map[string]string),
+		devDeps: make(map[string]string),
+	}
+	if rust := doc.str("package", "rust-version"); rust != "" {
+		m.info.Toolchain = "rust " + rust
+	}
+
+	for _, t := range doc {
+		switch {
+		case t.name == "dependencies" || t.name == "build-dependencies":
+			addTOMLDeps(m.deps, t.values)
+		case t.name == "dev-dependencies":
+			addTOMLDeps(m.devDeps, t.values)
+		case strings.HasPrefix(t.name, "dependencies."):
+			m.deps[strings.TrimPrefix(t.name, "dependencies.")] = tomlString(t.values["version"])
+		case strings.HasPrefix(t.name, "dev-dependencies."):
+			m.devDeps[strings.TrimPrefix(t.name, "dev-dependencies.")] = tomlString(t.values["version"])
+		}
+	}
+	return m
+}
+
+func addTOMLDeps(deps map[string]string, values map[string]interface{}) {
+	for name, value := range values {
+		switch v := value.(type) {
+		case string:
+			deps[name] = v
+		case map[string]interface{}:
+			deps[name] = tomlString(v["version"])
+		default:
+			deps[name] = ""
+		}
+	}
+}
+
+func tomlString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+// devDependencyGroups are optional-dependency groups treated as development-only.
+var devDependencyGroups = map[string]struct{}{
+	"dev": {}, "develop": {}, "test": {}, "tests": {}, "testing": {}, "lint": {}, "docs": {},
+}
+
+func parsePyproject(data []byte) manifestData {
+	doc := parseTOML(data)
+	m := manifestData{
+		info: model.Manifest{
+			Ecosystem:   "pypi",
+			Name:        doc.str("project", "name"),
+			Description: doc.str("project", "description"),
+			Version:     doc.str("project", "version"),
+		},
+		deps:    make(map[string]string),
+		devDeps: make(map[string]string),
+	}
+	if python := doc.str("project", "requires-python"); python != "" {
+		m.info.Toolchain = "python " + python
+	}
+	if list, ok := doc.table("project")["dependencies"].([]interface{}); ok {
+		addRequirementList(m.deps, list)
+	}
+	for group, value := range doc.table("project.optional-dependencies") {
+		if _, dev := devDependencyGroups[group]; !dev {
+			continue
+		}
+		if list, ok := value.([]interface{}); ok {
+			addRequirementList(m.devDeps, list)
+		}
+	}
+	for _, value := range doc.table("dependency-groups") {
+		if list, ok := value.([]interface{}); ok {
+			addRequirementList(m.devDeps, list)
+		}
+	}
+
+	// Poetry keeps its metadata under [tool.poetry].
+	if m.info.Name == "" {
+		m.info.Name = doc.str("tool.poetry", "name")
+	}
+	if m.info.Description == "" {
+		m.info.Description = doc.str("tool.poetry", "description")
+	}
+	if m.info.Version == "" {
+		m.info.Version = doc.str("tool.poetry", "version")
+	}
+	for name, value := range doc.table("tool.poetry.dependencies") {
+		if name == "python" {
+			if m.info.Toolchain == "" {
+				m.info.Toolchain = "python " + tomlString(value)
+			}
+			continue
+		}
+		m.deps[name] = poetryVersion(value)
+	}
+	for _, t := range doc {
+		if t.name == "tool.poetry.dev-dependencies" ||
+			(strings.HasPrefix(t.name, "tool.poetry.group.") && strings.HasSuffix(t.name, ".dependencies")) {
+			for name, value := range t.values {
+				m.devDeps[name] = poetryVersion(value)
+			}
+		}
+	}
+	return m
+}
+
+func poetryVersion(value interface{}) string {
+	if table, ok := value.(map[string]interface{}); ok {
+		return tomlString(table["version"])
+	}
+	return tomlString(value)
+}
+
+func addRequirementList(deps map[string]string, list []interface{}) {
+	for _, item := range list {
+		if req, ok := item.(string); ok {
+			if name, version := splitRequirement(req); name != "" {
+				deps[name] = version
+			}
+		}
+	}
+}
+
+// splitRequirement splits a PEP 508 requirement such as "requests>=2.0" into
+// the distribution name and its version specifier.
+func splitRequirement(req string) (string, string) {
+	req = strings.TrimSpace(req)
+	if idx := strings.Index(req, ";"); idx != -1 {
+		req = strings.TrimSpace(req[:idx])
+	}
+	end := strings.IndexAny(req, " =<>!~[@(")
+	if end == -1 {
+		return req, ""
+	}
+	name := req[:end]
+	rest := req[end:]
+	if idx := strings.Index(rest, "]"); strings.HasPrefix(rest, "[") && idx != -1 {
+		rest = rest[idx+1:]
+	}
+	return name, strings.TrimSpace(rest)
+}
+
+func parseRequirements(data []byte) manifestData {
+	m := manifestData{
+		info: model.Manifest{Ecosystem: "pypi"},
+		deps: make(map[string]string),
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, " #"); idx != -1 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if name, version := splitRequirement(line); name != "" {
+			m.deps[name] = version
+		}
+	}
+	return m
+}
+
+func parsePom(data []byte) manifestData {
+	var pom struct {
+		GroupID     string `xml:"groupId"`
+		ArtifactID  string `xml:"artifactId"`
+		Description string `xml:"description"`
+		Version     string `xml:"version"`
+		Parent      struct {
+			GroupID string `xml:"groupId"`
+		} `xml:"parent"`
+		Properties struct {
+			Entries []struct {
+				XMLName xml.Name
+				Value   string `xml:",chardata"`
+			} `xml:",any"`
+		} `xml:"properties"`
+		Dependencies []struct {
+			GroupID    string `xml:"groupId"`
+			ArtifactID string `xml:"artifactId"`
+			Version    string `xml:"version"`
+			Scope      string `xml:"scope"`
+		} `xml:"dependencies>dependency"`
+	}
+	m := manifestData{
+		info:    model.Manifest{Ecosystem: "maven"},
+		deps:    make(map[string]string),
+		devDeps: make(map[string]string),
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return m
+	}
+
+	group := pom.GroupID
+	if group == "" {
+		group = pom.Parent.GroupID
+	}
+	m.info.Name = pom.ArtifactID
+	if group != "" && pom.ArtifactID != "" {
+		m.info.Name = group + ":" + pom.ArtifactID
+	}
+	m.info.Version = pom.Version
+	m.info.Description = strings.Join(strings.Fields(pom.Description), " ")
+
+	properties := make(map[string]string)
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+	for _, key := range []string{"maven.compiler.release", "java.version", "maven.compiler.source"} {
+		if v := properties[key]; v != "" {
+			m.info.Toolchain = "java " + v
+			break
+		}
+	}
+
+	for _, dep := range pom.Dependencies {
+		name := dep.GroupID + ":" + dep.ArtifactID
+		if dep.Scope == "test" {
+			m.devDeps[name] = dep.Version
+		} else {
+			m.deps[name] = dep.Version
+		}
+	}
+	return m
+}
+
+var (
+	gradleDepPattern       = regexp.MustCompile(`^\s*(\w+)\s*[( ]\s*['"]([^'"]+)['"]`)
+	gradleVersionPattern   = regexp.MustCompile(`^\s*version\s*=?\s*['"]([^'"]+)['"]`)
+	gradleGroupPattern     = regexp.MustCompile(`^\s*group\s*=?\s*['"]([^'"]+)['"]`)
+	gradleToolchainPattern = regexp.MustCompile(`(?:JavaLanguageVersion\.of\(\s*(\d+)\s*\)|sourceCompatibility\s*=\s*(?:JavaVersion\.VERSION_)?['"]?([\d._]+))`)
+	gradleNamePattern      = regexp.MustCompile(`rootProject\.name\s*=\s*['"]([^'"]+)['"]`)
+)
+
+var gradleDepConfigurations = map[string]bool{
+	"implementation": false, "api": false, "compileOnly": false, "runtimeOnly": false,
+	"annotationProcessor": false, "kapt": false, "compile": false, "runtime": false,
+	"testImplementation": true, "testCompileOnly": true, "testRuntimeOnly": true,
+	"testAnnotationProcessor": true, "androidTestImplementation": true, "testCompile": true,
+}
+
+// parseGradle reads build.gradle and build.gradle.kts on a best-effort basis;
+// only string-literal dependency notations are recognized.
+func parseGradle(data []byte) manifestData {
+	m := manifestData{
+		info:    model.Manifest{Ecosystem: "gradle"},
+		deps:    make(map[string]string),
+		devDeps: make(map[string]string),
+	}
+	var group string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := gradleDepPattern.FindStringSubmatch(line); match != nil {
+			if dev, ok := gradleDepConfigurations[match[1]]; ok {
+				name, version := splitGradleNotation(match[2])
+				if dev {
+					m.devDeps[name] = version
+				} else {
+					m.deps[name] = version
+				}
+				continue
+			}
+		}
+		if match := gradleVersionPattern.FindStringSubmatch(line); match != nil && m.info.Version == "" {
+			m.info.Version = match[1]
+		}
+		if match := gradleGroupPattern.FindStringSubmatch(line); match != nil && group == "" {
+			group = match[1]
+		}
+		if match := gradleToolchainPattern.FindStringSubmatch(line); match != nil && m.info.Toolchain == "" {
+			version := match[1]
+			if version == "" {
+				version = strings.ReplaceAll(match[2], "_", ".")
+			}
+			m.info.Toolchain = "java " + version
+		}
+	}
+	m.info.Name = group
+	return m
+}
+
+func splitGradleNotation(notation string) (string, string) {
+	parts := strings.Split(notation, ":")
+	if len(parts) >= 3 {
+		return parts[0] + ":" + parts[1], parts[2]
+	}
+	return notation, ""
+}
+
+func gradleProjectName(root string) string {
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		if match := gradleNamePattern.FindSubmatch(data); match != nil {
+			return string(match[1])
+		}
+	}
+	return ""
+}
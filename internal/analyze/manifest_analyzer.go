@@ -0,0 +1,19 @@
+package analyze
+
+import "github.com/ErikOlson/proj-audit/internal/model"
+
+type ManifestAnalyzer struct{}
+
+func NewManifestAnalyzer() *ManifestAnalyzer {
+	return &ManifestAnalyzer{}
+}
+
+func (a *ManifestAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
+	var metrics model.ProjectMetrics
+	for _, m := range readManifests(path) {
+		metrics.Manifests = append(metrics.Manifests, m.info)
+		metrics.Dependencies += m.info.Dependencies
+		metrics.DevDependencies += m.info.DevDependencies
+	}
+	return metrics, nil
+}
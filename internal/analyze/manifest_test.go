@@ -0,0 +1,113 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestManifestAnalyzer(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/tool
+
+go 1.21
+
+require (
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/sys v0.15.0 // indirect
+)
+
+require github.com/stretchr/testify v1.9.0
+`,
+		"package.json": `{
+  "name": "web",
+  "version": "0.3.0",
+  "engines": {"node": ">=18"},
+  "dependencies": {"react": "^18.2.0", "react-dom": "^18.2.0"},
+  "devDependencies": {"vite": "^5.0.0"}
+}`,
+		"Cargo.toml": `[package]
+name = "crate"
+version = "0.1.0"
+rust-version = "1.70"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+anyhow = "1.0"
+
+[dependencies.tokio]
+version = "1"
+
+[dev-dependencies]
+proptest = "1"
+`,
+		"pyproject.toml": `[project]
+name = "svc"
+version = "2.0.0"
+requires-python = ">=3.10"
+dependencies = [
+    "requests>=2.31",
+    "click[extra]==8.1; python_version > '3.8'",
+]
+
+[project.optional-dependencies]
+dev = ["pytest"]
+docs = ["mkdocs"]
+server = ["uvicorn"]
+`,
+		"requirements.txt": "# pinned\nflask==3.0.0\n-r base.txt\nrequests\n",
+		"pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.2.3</version>
+  <properties><java.version>17</java.version></properties>
+  <dependencies>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.9</version></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.13</version><scope>test</scope></dependency>
+  </dependencies>
+</project>`,
+		"build.gradle.kts": `group = "com.example"
+version = "0.9"
+java { toolchain { languageVersion.set(JavaLanguageVersion.of(21)) } }
+dependencies {
+    implementation("com.google.guava:guava:33.0.0-jre")
+    testImplementation("org.junit.jupiter:junit-jupiter:5.10.0")
+}
+`,
+		"settings.gradle.kts": `rootProject.name = "lib"`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	metrics, err := NewManifestAnalyzer().Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	want := []model.Manifest{
+		{File: "go.mod", Ecosystem: "go", Name: "example.com/tool", Toolchain: "go 1.21", Dependencies: 2},
+		{File: "package.json", Ecosystem: "npm", Name: "web", Version: "0.3.0", Toolchain: "node >=18", Dependencies: 2, DevDependencies: 1},
+		{File: "Cargo.toml", Ecosystem: "cargo", Name: "crate", Version: "0.1.0", Toolchain: "rust 1.70", Dependencies: 3, DevDependencies: 1},
+		{File: "pyproject.toml", Ecosystem: "pypi", Name: "svc", Version: "2.0.0", Toolchain: "python >=3.10", Dependencies: 2, DevDependencies: 2},
+		{File: "requirements.txt", Ecosystem: "pypi", Dependencies: 2},
+		{File: "pom.xml", Ecosystem: "maven", Name: "com.example:app", Version: "1.2.3", Toolchain: "java 17", Dependencies: 1, DevDependencies: 1},
+		{File: "build.gradle.kts", Ecosystem: "gradle", Name: "com.example:lib", Version: "0.9", Toolchain: "java 21", Dependencies: 1, DevDependencies: 1},
+	}
+	if len(metrics.Manifests) != len(want) {
+		t.Fatalf("expected %d manifests, got %d: %+v", len(want), len(metrics.Manifests), metrics.Manifests)
+	}
+	for i := range want {
+		if metrics.Manifests[i] != want[i] {
+			t.Errorf("manifest %d:\n got  %+v\n want %+v", i, metrics.Manifests[i], want[i])
+		}
+	}
+	if metrics.Dependencies != 13 || metrics.DevDependencies != 6 {
+		t.Fatalf("unexpected totals: deps=%d dev=%d", metrics.Dependencies, metrics.DevDependencies)
+	}
+}
@@ -0,0 +1,293 @@
+package analyze
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+const defaultMinorLag = 2
+
+// VersionTable lists the current versions that pinned toolchains and
+// dependencies are compared against. Packages is keyed by ecosystem
+// ("go", "npm", "cargo") and then by package name. A version is stale when
+// it is a major version behind, or at least MinorLag minor versions behind.
+type VersionTable struct {
+	Toolchains map[string]string
+	Packages   map[string]map[string]string
+	MinorLag   int
+}
+
+type StalenessAnalyzer struct {
+	table VersionTable
+}
+
+func NewStalenessAnalyzer(table VersionTable) *StalenessAnalyzer {
+	if table.MinorLag <= 0 {
+		table.MinorLag = defaultMinorLag
+	}
+	return &StalenessAnalyzer{table: table}
+}
+
+func (a *StalenessAnalyzer) Analyze(path string) (model.ProjectMetrics, error) {
+	var metrics model.ProjectMetrics
+
+	toolchains := readToolchainVersions(path)
+	pinned := readPinnedVersions(path)
+
+	if len(toolchains) > 0 {
+		metrics.Toolchains = toolchains
+	}
+	for _, deps := range pinned {
+		metrics.PinnedDependencies += len(deps)
+	}
+
+	for _, name := range sortedKeys(toolchains) {
+		current, ok := a.table.Toolchains[name]
+		if ok && isStaleVersion(toolchains[name], current, a.table.MinorLag) {
+			metrics.Stale = append(metrics.Stale, model.StaleItem{
+				Kind:    "toolchain",
+				Name:    name,
+				Pinned:  toolchains[name],
+				Current: current,
+			})
+		}
+	}
+	for _, ecosystem := range sortedKeys(a.table.Packages) {
+		deps := pinned[ecosystem]
+		for _, name := range sortedKeys(a.table.Packages[ecosystem]) {
+			version, ok := deps[name]
+			current := a.table.Packages[ecosystem][name]
+			if ok && isStaleVersion(version, current, a.table.MinorLag) {
+				metrics.Stale = append(metrics.Stale, model.StaleItem{
+					Kind:      "package",
+					Ecosystem: ecosystem,
+					Name:      name,
+					Pinned:    version,
+					Current:   current,
+				})
+			}
+		}
+	}
+	metrics.IsStale = len(metrics.Stale) > 0
+
+	return metrics, nil
+}
+
+// toolchainAliases maps asdf/mise plugin names to the names used in reports.
+var toolchainAliases = map[string]string{
+	"golang": "go",
+	"nodejs": "node",
+}
+
+// readToolchainVersions collects toolchain versions from go.mod and version
+// manager files. Later sources win: a dedicated .nvmrc or .python-version is
+// more specific than .tool-versions.
+func readToolchainVersions(root string) map[string]string {
+	versions := make(map[string]string)
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		if tc := parseGoMod(data).info.Toolchain; tc != "" {
+			versions["go"] = strings.TrimPrefix(tc, "go ")
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, ".tool-versions")); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			name := fields[0]
+			if alias, ok := toolchainAliases[name]; ok {
+				name = alias
+			}
+			versions[name] = fields[1]
+		}
+	}
+
+	for file, name := range map[string]string{".nvmrc": "node", ".python-version": "python"} {
+		if version := firstLine(filepath.Join(root, file)); version != "" {
+			versions[name] = strings.TrimPrefix(version, "v")
+		}
+	}
+
+	return versions
+}
+
+func firstLine(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+// readPinnedVersions returns the exact versions recorded in lockfiles (and
+// go.mod, whose requirements are exact), keyed by ecosystem and name.
+func readPinnedVersions(root string) map[string]map[string]string {
+	pinned := make(map[string]map[string]string)
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		if deps := parseGoMod(data).deps; len(deps) > 0 {
+			pinned["go"] = deps
+		}
+	}
+
+	npm := make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(root, "package-lock.json")); err == nil {
+		parsePackageLock(data, npm)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "yarn.lock")); err == nil {
+		parseYarnLock(data, npm)
+	}
+	if len(npm) > 0 {
+		pinned["npm"] = npm
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "Cargo.lock")); err == nil {
+		cargo := make(map[string]string)
+		for _, pkg := range parseTOML(data).tables("package") {
+			if name := tomlString(pkg["name"]); name != "" {
+				cargo[name] = tomlString(pkg["version"])
+			}
+		}
+		if len(cargo) > 0 {
+			pinned["cargo"] = cargo
+		}
+	}
+
+	return pinned
+}
+
+func parsePackageLock(data []byte, out map[string]string) {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return
+	}
+	// lockfileVersion 2+ lists every installed path; only top-level
+	// node_modules entries are the project's own resolutions.
+	for key, pkg := range lock.Packages {
+		name := strings.TrimPrefix(key, "node_modules/")
+		if name == key || strings.Contains(name, "/node_modules/") || pkg.Version == "" {
+			continue
+		}
+		out[name] = pkg.Version
+	}
+	if len(lock.Packages) > 0 {
+		return
+	}
+	for name, dep := range lock.Dependencies {
+		out[name] = dep.Version
+	}
+}
+
+// parseYarnLock reads both classic (v1) and berry yarn.lock files.
+func parseYarnLock(data []byte, out map[string]string) {
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			spec := strings.TrimSuffix(trimmed, ":")
+			if idx := strings.Index(spec, ","); idx != -1 {
+				spec = spec[:idx]
+			}
+			current = yarnPackageName(strings.Trim(spec, `"`))
+			continue
+		}
+		if current == "" || !strings.HasPrefix(trimmed, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(trimmed, "version"))
+		version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+		if _, seen := out[current]; !seen {
+			out[current] = version
+		}
+	}
+}
+
+func yarnPackageName(spec string) string {
+	if spec == "__metadata" {
+		return ""
+	}
+	// Scoped packages start with "@", so look for the separator after it.
+	idx := strings.Index(spec[min(1, len(spec)):], "@")
+	if idx == -1 {
+		return spec
+	}
+	return spec[:idx+1]
+}
+
+// isStaleVersion reports whether pinned lags current by a major version or by
+// at least minorLag minor versions. Unparseable versions are never stale.
+func isStaleVersion(pinned, current string, minorLag int) bool {
+	p, ok := parseVersion(pinned)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	if p[0] != c[0] {
+		return p[0] < c[0]
+	}
+	return c[1]-p[1] >= minorLag
+}
+
+// parseVersion extracts major and minor numbers from strings such as
+// "v1.2.3", "go1.21", "^18.2.0" or "3.11".
+func parseVersion(version string) ([2]int, bool) {
+	start := strings.IndexAny(version, "0123456789")
+	if start == -1 {
+		return [2]int{}, false
+	}
+	version = version[start:]
+	if end := strings.IndexAny(version, "-+ "); end != -1 {
+		version = version[:end]
+	}
+	parts := strings.Split(version, ".")
+	var out [2]int
+	for i := 0; i < 2 && i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return [2]int{}, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestStalenessAnalyzer(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module x\n\ngo 1.18\n\nrequire github.com/spf13/cobra v1.2.1\n",
+		".tool-versions": "nodejs 16.20.0\ngolang 1.22.0\n",
+		".nvmrc":         "v14.21.3\n",
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+			"": {"name": "app"},
+			"node_modules/react": {"version": "16.14.0"},
+			"node_modules/foo/node_modules/react": {"version": "18.0.0"}
+		}}`,
+		"yarn.lock": `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.1.0"
+
+lodash@^4.17.0:
+  version "4.17.21"
+`,
+		"Cargo.lock": "[[package]]\nname = \"serde\"\nversion = \"1.0.100\"\n\n[[package]]\nname = \"tokio\"\nversion = \"0.2.0\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	table := VersionTable{
+		Toolchains: map[string]string{"go": "1.23.0", "node": "22.0.0"},
+		Packages: map[string]map[string]string{
+			"go":    {"github.com/spf13/cobra": "1.8.0"},
+			"npm":   {"react": "18.3.1", "lodash": "4.17.21", "@babel/core": "7.24.0"},
+			"cargo": {"serde": "1.0.200", "tokio": "1.37.0"},
+		},
+	}
+	metrics, err := NewStalenessAnalyzer(table).Analyze(root)
+	if err != nil {
+		t.Fatalf("Analyze returned error: %v", err)
+	}
+
+	if metrics.Toolchains["go"] != "1.22.0" || metrics.Toolchains["node"] != "14.21.3" {
+		t.Fatalf("unexpected toolchains: %v", metrics.Toolchains)
+	}
+	if metrics.PinnedDependencies != 6 {
+		t.Fatalf("expected 6 pinned dependencies, got %d", metrics.PinnedDependencies)
+	}
+
+	want := []model.StaleItem{
+		{Kind: "toolchain", Name: "node", Pinned: "14.21.3", Current: "22.0.0"},
+		{Kind: "package", Ecosystem: "cargo", Name: "tokio", Pinned: "0.2.0", Current: "1.37.0"},
+		{Kind: "package", Ecosystem: "go", Name: "github.com/spf13/cobra", Pinned: "v1.2.1", Current: "1.8.0"},
+		{Kind: "package", Ecosystem: "npm", Name: "@babel/core", Pinned: "7.1.0", Current: "7.24.0"},
+		{Kind: "package", Ecosystem: "npm", Name: "react", Pinned: "16.14.0", Current: "18.3.1"},
+	}
+	if !metrics.IsStale || len(metrics.Stale) != len(want) {
+		t.Fatalf("unexpected stale items: %+v", metrics.Stale)
+	}
+	for i := range want {
+		if metrics.Stale[i] != want[i] {
+			t.Errorf("stale %d: got %+v, want %+v", i, metrics.Stale[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package analyze
+
+import "strings"
+
+// tomlTable is a single [table] or [[array-table]] section of a TOML document.
+// Only the subset needed to read project manifests is supported: string,
+// array and inline-table values. Other scalars are kept as raw text.
+type tomlTable struct {
+	name   string
+	array  bool
+	values map[string]interface{}
+}
+
+type tomlDoc []tomlTable
+
+func parseTOML(data []byte) tomlDoc {
+	doc := tomlDoc{{name: "", values: make(map[string]interface{})}}
+	current := &doc[0]
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			array := strings.HasPrefix(line, "[[")
+			name := strings.Trim(line, "[] \t")
+			doc = append(doc, tomlTable{name: name, array: array, values: make(map[string]interface{})})
+			current = &doc[len(doc)-1]
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq == -1 {
+			continue
+		}
+		key := strings.Trim(strings.TrimSpace(line[:eq]), `"'`)
+		raw := strings.TrimSpace(line[eq+1:])
+		// Arrays and inline tables may span multiple lines.
+		for !tomlBalanced(raw) && i+1 < len(lines) {
+			i++
+			raw += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		current.values[key] = parseTOMLValue(raw)
+	}
+	return doc
+}
+
+// table returns the values of the first table with the given name.
+func (d tomlDoc) table(name string) map[string]interface{} {
+	for _, t := range d {
+		if t.name == name {
+			return t.values
+		}
+	}
+	return nil
+}
+
+// tables returns every table with the given name, in document order.
+func (d tomlDoc) tables(name string) []map[string]interface{} {
+	var out []map[string]interface{}
+	for _, t := range d {
+		if t.name == name {
+			out = append(out, t.values)
+		}
+	}
+	return out
+}
+
+func (d tomlDoc) str(table, key string) string {
+	values := d.table(table)
+	if values == nil {
+		return ""
+	}
+	s, _ := values[key].(string)
+	return s
+}
+
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func tomlBalanced(raw string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func parseTOMLValue(raw string) interface{} {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "":
+		return ""
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''"):
+		return strings.Trim(raw, `"'`)
+	case raw[0] == '"':
+		return unquoteTOML(raw)
+	case raw[0] == '\'':
+		return strings.Trim(raw, "'")
+	case raw[0] == '[':
+		var items []interface{}
+		for _, part := range splitTOMLList(strings.TrimSuffix(raw[1:], "]")) {
+			items = append(items, parseTOMLValue(part))
+		}
+		return items
+	case raw[0] == '{':
+		table := make(map[string]interface{})
+		for _, part := range splitTOMLList(strings.TrimSuffix(raw[1:], "}")) {
+			eq := strings.Index(part, "=")
+			if eq == -1 {
+				continue
+			}
+			key := strings.Trim(strings.TrimSpace(part[:eq]), `"'`)
+			table[key] = parseTOMLValue(part[eq+1:])
+		}
+		return table
+	}
+	return raw
+}
+
+func unquoteTOML(raw string) string {
+	raw = strings.TrimPrefix(raw, `"`)
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if c == '"' {
+			break
+		}
+		if c == '\\' && i+1 < len(raw) {
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(raw[i])
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// splitTOMLList splits the body of an array or inline table on top-level commas.
+func splitTOMLList(body string) []string {
+	var parts []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, body[start:])
+
+	out := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
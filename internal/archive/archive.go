@@ -0,0 +1,369 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// ManifestName is the archive entry holding the Manifest. It is written first
+// so it can be read without unpacking the whole archive.
+const ManifestName = "proj-audit-manifest.json"
+
+// Manifest describes an archived project.
+type Manifest struct {
+	Name       string               `json:"name"`
+	Path       string               `json:"path"`
+	Category   string               `json:"category"`
+	ArchivedAt time.Time            `json:"archivedAt"`
+	Files      int                  `json:"files"`
+	Bytes      int64                `json:"bytes"`
+	Scores     model.ProjectScores  `json:"scores"`
+	Metrics    model.ProjectMetrics `json:"metrics"`
+	// Excluded lists the directories left out of the archive, relative to
+	// Path.
+	Excluded []string `json:"excluded,omitempty"`
+}
+
+// Result is the outcome of archiving one project.
+type Result struct {
+	Archive  string
+	Manifest Manifest
+}
+
+// Create writes <destDir>/<name>-<date>.tar.gz containing the project's files
+// beneath a top-level directory named after the project. Directories whose
+// name is in exclude (regenerable build artifacts) are left out. An existing
+// archive is never overwritten.
+func Create(p *model.Project, destDir string, exclude []string) (Result, error) {
+	src, err := filepath.Abs(p.Path)
+	if err != nil {
+		return Result{}, fmt.Errorf("resolve %s: %w", p.Path, err)
+	}
+	name := filepath.Base(src)
+	manifest := Manifest{
+		Name:       name,
+		Path:       src,
+		Category:   p.Category,
+		ArchivedAt: time.Now(),
+		Scores:     p.Scores,
+		Metrics:    p.Metrics,
+	}
+
+	skip := make(map[string]struct{}, len(exclude))
+	for _, dir := range exclude {
+		skip[dir] = struct{}{}
+	}
+	var entries []string
+	err = filepath.WalkDir(src, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && current != src {
+			if _, ok := skip[d.Name()]; ok {
+				rel, err := filepath.Rel(src, current)
+				if err != nil {
+					return err
+				}
+				manifest.Excluded = append(manifest.Excluded, filepath.ToSlash(rel))
+				return filepath.SkipDir
+			}
+		}
+		if current != src {
+			entries = append(entries, current)
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			manifest.Files++
+			manifest.Bytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("walk %s: %w", src, err)
+	}
+
+	dest, err := filepath.Abs(destDir)
+	if err != nil {
+		return Result{}, fmt.Errorf("resolve %s: %w", destDir, err)
+	}
+	if rel, err := filepath.Rel(src, dest); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Result{}, fmt.Errorf("archive dir %s is inside %s", dest, src)
+	}
+
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return Result{}, fmt.Errorf("create archive dir: %w", err)
+	}
+	archivePath := filepath.Join(destDir, fmt.Sprintf("%s-%s.tar.gz", name, manifest.ArchivedAt.Format("20060102")))
+	out, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return Result{}, fmt.Errorf("create archive: %w", err)
+	}
+
+	if err := writeArchive(out, src, name, manifest, entries); err != nil {
+		out.Close()
+		os.Remove(archivePath)
+		return Result{}, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(archivePath)
+		return Result{}, fmt.Errorf("close archive: %w", err)
+	}
+	return Result{Archive: archivePath, Manifest: manifest}, nil
+}
+
+// Move relocates the original of an archived project into destDir, next to
+// the archive and named after it. When destDir is on another filesystem the
+// tree is copied there instead, and the original removed once the copy is
+// complete.
+func Move(result Result, destDir string) (string, error) {
+	src := result.Manifest.Path
+	target, err := filepath.Abs(filepath.Join(destDir, strings.TrimSuffix(filepath.Base(result.Archive), ".tar.gz")))
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", destDir, err)
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return "", fmt.Errorf("move %s: %s already exists", src, target)
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("move %s: %w", src, err)
+	}
+
+	err = os.Rename(src, target)
+	if err == nil {
+		return target, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return "", fmt.Errorf("move %s: %w", src, err)
+	}
+	if err := copyTree(src, target); err != nil {
+		os.RemoveAll(target)
+		return "", fmt.Errorf("move %s: %w", src, err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return target, fmt.Errorf("move %s: copied to %s but could not remove the original: %w", src, target, err)
+	}
+	return target, nil
+}
+
+// copyTree copies the directory src to dst, which must not exist, keeping
+// file modes and symlinks. Like the archive, it skips sockets, devices and
+// pipes.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(current string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, current)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(current)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(current, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func writeArchive(w io.Writer, src, name string, manifest Manifest, entries []string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.ArchivedAt}); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	for _, current := range entries {
+		if err := addEntry(tw, src, name, current); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("finish archive: %w", err)
+	}
+	return nil
+}
+
+func addEntry(tw *tar.Writer, src, name, current string) error {
+	info, err := os.Lstat(current)
+	if err != nil {
+		return err
+	}
+	var link string
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if link, err = os.Readlink(current); err != nil {
+			return err
+		}
+	case info.IsDir(), info.Mode().IsRegular():
+	default:
+		// Sockets, devices and pipes cannot be meaningfully archived.
+		return nil
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", current, err)
+	}
+	rel, err := filepath.Rel(src, current)
+	if err != nil {
+		return err
+	}
+	header.Name = path.Join(name, filepath.ToSlash(rel))
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("archive %s: %w", current, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(current)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("archive %s: %w", current, err)
+	}
+	return nil
+}
+
+// Verify reads the whole archive back and checks that it holds the files and
+// bytes its manifest promises.
+func Verify(archivePath string) (Manifest, error) {
+	var manifest Manifest
+	var files int
+	var size int64
+	err := walkArchive(archivePath, func(header *tar.Header, r io.Reader) error {
+		if header.Name == ManifestName {
+			return json.NewDecoder(r).Decode(&manifest)
+		}
+		if header.Typeflag == tar.TypeReg {
+			n, err := io.Copy(io.Discard, r)
+			if err != nil {
+				return err
+			}
+			files++
+			size += n
+		}
+		return nil
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+	if manifest.Name == "" {
+		return Manifest{}, fmt.Errorf("verify %s: missing %s", archivePath, ManifestName)
+	}
+	if files != manifest.Files || size != manifest.Bytes {
+		return Manifest{}, fmt.Errorf("verify %s: holds %d files (%d bytes), manifest lists %d (%d bytes)", archivePath, files, size, manifest.Files, manifest.Bytes)
+	}
+	return manifest, nil
+}
+
+// ReadManifest returns the manifest stored at the start of an archive.
+func ReadManifest(archivePath string) (Manifest, error) {
+	var manifest Manifest
+	errFound := errors.New("found")
+	err := walkArchive(archivePath, func(header *tar.Header, r io.Reader) error {
+		if header.Name != ManifestName {
+			return nil
+		}
+		if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+			return err
+		}
+		return errFound
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return Manifest{}, err
+	}
+	if manifest.Name == "" {
+		return Manifest{}, fmt.Errorf("read %s: missing %s", archivePath, ManifestName)
+	}
+	return manifest, nil
+}
+
+func walkArchive(archivePath string, fn func(*tar.Header, io.Reader) error) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", archivePath, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			// Drain the stream so gzip verifies its checksum.
+			if _, err := io.Copy(io.Discard, gz); err != nil {
+				return fmt.Errorf("read %s: %w", archivePath, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", archivePath, err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestCreateVerifyRestore(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "old-app")
+	files := map[string]string{
+		"main.go":                   "package main\n",
+		"docs/README.md":            "# old-app\n",
+		"node_modules/pkg/index.js": "module.exports = 1\n",
+	}
+	for name, content := range files {
+		full := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.Symlink("docs/README.md", filepath.Join(src, "README.md")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	project := &model.Project{Path: src, Category: "Archived", Metrics: model.ProjectMetrics{CommitCount: 4}}
+	result, err := Create(project, filepath.Join(root, "archive"), []string{"node_modules"})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if result.Manifest.Files != 2 {
+		t.Fatalf("expected 2 files (artifacts excluded), got %d", result.Manifest.Files)
+	}
+
+	manifest, err := Verify(result.Archive)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if manifest.Path != src || manifest.Category != "Archived" || manifest.Metrics.CommitCount != 4 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+
+	if _, err := Restore(result.Archive, ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected restore over the original to be refused, got %v", err)
+	}
+
+	target := filepath.Join(root, "restored")
+	if _, err := Restore(result.Archive, target); err != nil {
+		t.Fatalf("Restore returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(target, "README.md"))
+	if err != nil || string(data) != files["docs/README.md"] {
+		t.Fatalf("expected README symlink to resolve, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "node_modules")); !os.IsNotExist(err) {
+		t.Fatalf("expected node_modules to be excluded, stat err = %v", err)
+	}
+}
+
+func TestCreateRejectsDestinationInsideProject(t *testing.T) {
+	src := t.TempDir()
+	project := &model.Project{Path: src}
+	for _, dest := range []string{"archive", "..archive"} {
+		if _, err := Create(project, filepath.Join(src, dest), nil); err == nil {
+			t.Fatalf("expected an error for the destination %s inside the project", dest)
+		}
+	}
+}
+
+func TestMoveKeepsExcludedDirectories(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "old-app")
+	for _, name := range []string{"main.go", "bin/run.sh"} {
+		full := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	dest := filepath.Join(root, "archive")
+
+	result, err := Create(&model.Project{Path: src}, dest, []string{"bin"})
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(result.Manifest.Excluded) != 1 || result.Manifest.Excluded[0] != "bin" {
+		t.Fatalf("expected bin to be recorded as excluded, got %v", result.Manifest.Excluded)
+	}
+	moved, err := Move(result, dest)
+	if err != nil {
+		t.Fatalf("Move returned error: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected the original to be gone, stat err = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(moved, "bin", "run.sh")); err != nil || string(data) != "bin/run.sh" {
+		t.Fatalf("expected bin/run.sh under %s, got %q, %v", moved, data, err)
+	}
+	if _, err := Move(result, dest); err == nil {
+		t.Fatalf("expected a second move onto %s to be refused", moved)
+	}
+}
+
+func TestCopyTreeKeepsModesAndSymlinks(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(filepath.Join(src, "node_modules", "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "node_modules", "pkg", "index.js"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(src, "start")); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := copyTree(src, dst); err != nil {
+		t.Fatalf("copyTree returned error: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "run.sh")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected an executable run.sh, got %v, %v", info, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "node_modules", "pkg", "index.js")); err != nil || string(data) != "x" {
+		t.Fatalf("expected node_modules to be copied, got %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dst, "start")); err != nil || link != "run.sh" {
+		t.Fatalf("expected start -> run.sh, got %q, %v", link, err)
+	}
+}
+
+// writeTestArchive writes an archive of project "app" holding entries as
+// given, for exercising Restore on archives Create would never write.
+func writeTestArchive(t *testing.T, path string, entries []tar.Header) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	manifest, _ := json.Marshal(Manifest{Name: "app", Path: "/nonexistent/app"})
+	headers := append([]tar.Header{{Name: ManifestName, Size: int64(len(manifest))}}, entries...)
+	for i := range headers {
+		header := headers[i]
+		header.Mode = 0o644
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Name == ManifestName {
+			tw.Write(manifest)
+		} else if header.Size > 0 {
+			tw.Write(make([]byte, header.Size))
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreRejectsSymlinkTraversal(t *testing.T) {
+	for name, entries := range map[string][]tar.Header{
+		"absolute": {{Name: "app/link", Typeflag: tar.TypeSymlink, Linkname: "/etc"}},
+		"escaping": {{Name: "app/sub/link", Typeflag: tar.TypeSymlink, Linkname: "../../x"}},
+		"through a link": {
+			{Name: "app/dir/", Typeflag: tar.TypeDir},
+			{Name: "app/link", Typeflag: tar.TypeSymlink, Linkname: "dir"},
+			{Name: "app/link/file", Typeflag: tar.TypeReg, Size: 1},
+		},
+	} {
+		root := t.TempDir()
+		archivePath := filepath.Join(root, "app.tar.gz")
+		writeTestArchive(t, archivePath, entries)
+		target := filepath.Join(root, "restored")
+		if _, err := Restore(archivePath, target); err == nil || !strings.Contains(err.Error(), "unsafe") {
+			t.Errorf("%s: expected an unsafe entry error, got %v", name, err)
+		}
+		if _, err := os.Stat(target); !os.IsNotExist(err) {
+			t.Errorf("%s: expected nothing restored, stat err = %v", name, err)
+		}
+	}
+}
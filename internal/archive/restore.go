@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Restore unpacks an archive to target, or to the project's original path
+// when target is empty. It refuses to touch an existing target. Files are
+// unpacked into a temporary sibling directory first, so a failed restore
+// leaves nothing half-written at the target.
+func Restore(archivePath, target string) (string, error) {
+	manifest, err := ReadManifest(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if target == "" {
+		target = manifest.Path
+	}
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return "", fmt.Errorf("restore: %s already exists", target)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	parent := filepath.Dir(target)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", fmt.Errorf("restore: %w", err)
+	}
+	staging, err := os.MkdirTemp(parent, ".proj-audit-restore-")
+	if err != nil {
+		return "", fmt.Errorf("restore: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	prefix := manifest.Name + "/"
+	err = walkArchive(archivePath, func(header *tar.Header, r io.Reader) error {
+		if header.Name == ManifestName {
+			return nil
+		}
+		name := strings.TrimPrefix(header.Name, prefix)
+		if name == header.Name {
+			return fmt.Errorf("unexpected entry %q", header.Name)
+		}
+		return extract(staging, name, header, r)
+	})
+	if err != nil {
+		return "", fmt.Errorf("restore: %w", err)
+	}
+	if err := os.Chmod(staging, 0o755); err != nil {
+		return "", fmt.Errorf("restore: %w", err)
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return "", fmt.Errorf("restore: %w", err)
+	}
+	return target, nil
+}
+
+func extract(root, name string, header *tar.Header, r io.Reader) error {
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		return nil
+	}
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("unsafe entry %q", header.Name)
+	}
+	dest := filepath.Join(root, filepath.FromSlash(clean))
+	mode := os.FileMode(header.Mode).Perm()
+	// Nothing is written through a symlink unpacked earlier, so a link
+	// cannot redirect a later entry outside root.
+	for dir := path.Dir(clean); dir != "."; dir = path.Dir(dir) {
+		info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(dir)))
+		if err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("unsafe entry %q: %s is a symlink", header.Name, dir)
+		}
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dest, mode|0o700)
+	case tar.TypeSymlink:
+		link := header.Linkname
+		resolved := path.Join(path.Dir(clean), link)
+		if path.IsAbs(link) || filepath.IsAbs(link) || resolved == ".." || strings.HasPrefix(resolved, "../") {
+			return fmt.Errorf("unsafe symlink %q -> %q", header.Name, link)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		return os.Symlink(link, dest)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(dest, header.ModTime, header.ModTime)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package clean
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ErikOlson/proj-audit/internal/analyze"
+	"github.com/ErikOlson/proj-audit/internal/filter"
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// Target is an artifact directory selected for removal.
+type Target struct {
+	Project  string `json:"project"`
+	Category string `json:"category"`
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// Skip records a project or directory that was left alone, and why.
+type Skip struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type Plan struct {
+	Targets []Target
+	Skipped []Skip
+	// NonGit is set when projects outside git were allowed.
+	NonGit bool
+}
+
+// ReasonNotGit is the Skip reason for a project outside any git work tree,
+// where nothing tells generated directories from hand-written ones.
+const ReasonNotGit = "not in a git repository"
+
+func (p Plan) TotalBytes() int64 {
+	var total int64
+	for _, t := range p.Targets {
+		total += t.Bytes
+	}
+	return total
+}
+
+// Entry is the outcome of removing one target.
+type Entry struct {
+	Target
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Log is the JSON record written after a clean run.
+type Log struct {
+	Time       time.Time `json:"time"`
+	FreedBytes int64     `json:"freedBytes"`
+	Entries    []Entry   `json:"entries"`
+	Skipped    []Skip    `json:"skipped,omitempty"`
+}
+
+// NewPlan selects the artifact directories of every project matching f.
+// Projects with uncommitted changes are skipped entirely, as are projects
+// outside git unless nonGit is set, and artifact directories that contain
+// tracked files or belong to a nested project.
+func NewPlan(root *model.Node, f filter.Filter, nonGit bool) Plan {
+	plan := Plan{NonGit: nonGit}
+	projectDirs := make(map[string]struct{})
+	for _, p := range filter.Projects(root, filter.Filter{}) {
+		projectDirs[absPath(p.Path)] = struct{}{}
+	}
+
+	for _, p := range filter.Projects(root, f) {
+		if len(p.Metrics.Artifacts) == 0 {
+			continue
+		}
+		dir := absPath(p.Path)
+		dirty, err := analyze.GitDirty(dir)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, Skip{Path: dir, Reason: fmt.Sprintf("git status failed: %v", err)})
+			continue
+		}
+		if dirty {
+			plan.Skipped = append(plan.Skipped, Skip{Path: dir, Reason: "uncommitted changes"})
+			continue
+		}
+		inRepo, err := analyze.GitWorkTree(dir)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, Skip{Path: dir, Reason: fmt.Sprintf("git rev-parse failed: %v", err)})
+			continue
+		}
+		if !inRepo && !nonGit {
+			plan.Skipped = append(plan.Skipped, Skip{Path: dir, Reason: ReasonNotGit})
+			continue
+		}
+		for _, artifact := range p.Metrics.Artifacts {
+			path := filepath.Join(dir, filepath.FromSlash(artifact.Path))
+			if !within(dir, path) {
+				plan.Skipped = append(plan.Skipped, Skip{Path: path, Reason: "outside project"})
+				continue
+			}
+			if nestedProject(dir, path, projectDirs) {
+				continue
+			}
+			tracked, err := analyze.GitTracked(path)
+			if err != nil {
+				plan.Skipped = append(plan.Skipped, Skip{Path: path, Reason: fmt.Sprintf("git ls-files failed: %v", err)})
+				continue
+			}
+			if tracked {
+				plan.Skipped = append(plan.Skipped, Skip{Path: path, Reason: "contains tracked files"})
+				continue
+			}
+			plan.Targets = append(plan.Targets, Target{
+				Project:  dir,
+				Category: p.Category,
+				Path:     path,
+				Bytes:    artifact.Bytes,
+			})
+		}
+	}
+	return plan
+}
+
+// Execute removes every target in the plan. Targets that are no longer plain
+// directories (for example replaced by a symlink since the scan) are left alone.
+func Execute(plan Plan) Log {
+	log := Log{Time: time.Now(), Skipped: plan.Skipped}
+	for _, target := range plan.Targets {
+		entry := Entry{Target: target}
+		info, err := os.Lstat(target.Path)
+		switch {
+		case err != nil:
+			entry.Error = err.Error()
+		case !info.IsDir():
+			entry.Error = "not a directory"
+		default:
+			if err := os.RemoveAll(target.Path); err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.Deleted = true
+				log.FreedBytes += target.Bytes
+			}
+		}
+		log.Entries = append(log.Entries, entry)
+	}
+	return log
+}
+
+func WriteLog(path string, log Log) error {
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode clean log: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write clean log: %w", err)
+	}
+	return nil
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// nestedProject reports whether path lies inside a project nested below
+// owner; such directories are cleaned only if that project itself matches.
+func nestedProject(owner, path string, projectDirs map[string]struct{}) bool {
+	for dir := filepath.Dir(path); dir != owner && within(owner, dir); dir = filepath.Dir(dir) {
+		if _, ok := projectDirs[dir]; ok {
+			return true
+		}
+	}
+	return false
+}
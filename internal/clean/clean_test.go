@@ -0,0 +1,126 @@
+package clean
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/filter"
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestPlanAndExecute(t *testing.T) {
+	root := t.TempDir()
+	oldApp := filepath.Join(root, "old-app")
+	nested := filepath.Join(oldApp, "web")
+	live := filepath.Join(root, "live")
+	writeFile(t, filepath.Join(oldApp, "node_modules", "pkg", "index.js"), 100)
+	writeFile(t, filepath.Join(nested, "node_modules", "pkg", "index.js"), 50)
+	writeFile(t, filepath.Join(live, "target", "debug", "app"), 70)
+
+	tree := &model.Node{Path: root, Children: []*model.Node{
+		{Path: oldApp, Project: &model.Project{Path: oldApp, Category: "Archived", Metrics: model.ProjectMetrics{
+			Artifacts: []model.Artifact{{Path: "node_modules", Bytes: 100}, {Path: "web/node_modules", Bytes: 50}},
+		}}, Children: []*model.Node{
+			{Path: nested, Project: &model.Project{Path: nested, Category: "Serious", Metrics: model.ProjectMetrics{
+				Artifacts: []model.Artifact{{Path: "node_modules", Bytes: 50}},
+			}}},
+		}},
+		{Path: live, Project: &model.Project{Path: live, Category: "Serious", Metrics: model.ProjectMetrics{
+			Artifacts: []model.Artifact{{Path: "target", Bytes: 70}},
+		}}},
+	}}
+
+	plan := NewPlan(tree, filter.Filter{Categories: []string{"archived"}}, true)
+	if len(plan.Targets) != 1 || plan.Targets[0].Path != filepath.Join(oldApp, "node_modules") {
+		t.Fatalf("expected only old-app/node_modules, got %+v", plan.Targets)
+	}
+	if plan.TotalBytes() != 100 {
+		t.Fatalf("expected 100 bytes, got %d", plan.TotalBytes())
+	}
+
+	log := Execute(plan)
+	if log.FreedBytes != 100 || len(log.Entries) != 1 || !log.Entries[0].Deleted {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	if _, err := os.Stat(filepath.Join(oldApp, "node_modules")); !os.IsNotExist(err) {
+		t.Fatalf("expected node_modules to be removed, stat err = %v", err)
+	}
+	for _, kept := range []string{filepath.Join(nested, "node_modules"), filepath.Join(live, "target")} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Fatalf("expected %s to be kept: %v", kept, err)
+		}
+	}
+}
+
+func TestPlanSkipsDirtyAndTrackedTrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules/\n"), 0o644); err != nil {
+		t.Fatalf("write .gitignore: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "node_modules", "x.js"), 10)
+	writeFile(t, filepath.Join(root, "vendor", "lib.go"), 20)
+	git("add", ".gitignore", "vendor")
+	git("commit", "-q", "-m", "init")
+
+	tree := &model.Node{Path: root, Project: &model.Project{Path: root, Category: "Archived", Metrics: model.ProjectMetrics{
+		Artifacts: []model.Artifact{{Path: "node_modules", Bytes: 10}, {Path: "vendor", Bytes: 20}},
+	}}}
+
+	plan := NewPlan(tree, filter.Filter{}, false)
+	if len(plan.Targets) != 1 || plan.Targets[0].Path != filepath.Join(root, "node_modules") {
+		t.Fatalf("expected only node_modules, got %+v", plan.Targets)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Reason != "contains tracked files" {
+		t.Fatalf("expected vendor to be skipped as tracked, got %+v", plan.Skipped)
+	}
+
+	writeFile(t, filepath.Join(root, "main.go"), 5)
+	plan = NewPlan(tree, filter.Filter{}, false)
+	if len(plan.Targets) != 0 || len(plan.Skipped) != 1 || plan.Skipped[0].Reason != "uncommitted changes" {
+		t.Fatalf("expected dirty project to be skipped, got %+v", plan)
+	}
+}
+
+func TestPlanSkipsNonGitProjectsUnlessAllowed(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "bin", "deploy.sh"), 30)
+	tree := &model.Node{Path: root, Project: &model.Project{Path: root, Category: "Archived", Metrics: model.ProjectMetrics{
+		Artifacts: []model.Artifact{{Path: "bin", Bytes: 30}},
+	}}}
+
+	plan := NewPlan(tree, filter.Filter{}, false)
+	if len(plan.Targets) != 0 || len(plan.Skipped) != 1 || plan.Skipped[0].Reason != ReasonNotGit {
+		t.Fatalf("expected the non-git project to be skipped, got %+v", plan)
+	}
+
+	plan = NewPlan(tree, filter.Filter{}, true)
+	if !plan.NonGit || len(plan.Targets) != 1 || plan.Targets[0].Path != filepath.Join(root, "bin") {
+		t.Fatalf("expected bin to be targeted with nonGit, got %+v", plan)
+	}
+}
@@ -1,28 +1,68 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type LanguageConfig struct {
-	Extensions []string `json:"extensions"`
-	SkipDirs   []string `json:"skipDirs"`
+	Extensions  []string `json:"extensions"`
+	SkipDirs    []string `json:"skipDirs"`
+	TestFiles   []string `json:"testFiles"`
+	TestDirs    []string `json:"testDirs"`
+	TestMarkers []string `json:"testMarkers"`
+}
+
+type CIConfig struct {
+	Markers []string `json:"markers"`
+}
+
+type FrameworkConfig struct {
+	Type         string   `json:"type"`
+	Dependencies []string `json:"dependencies"`
+	Files        []string `json:"files"`
+	Imports      []string `json:"imports"`
+	Extensions   []string `json:"extensions"`
 }
 
 type Config struct {
-	Root          string                    `json:"root"`
-	MaxDepth      int                       `json:"maxDepth"`
-	Format        string                    `json:"format"`
-	IgnoreDirs    []string                  `json:"ignoreDirs"`
-	IncludeHidden bool                      `json:"includeHidden"`
-	LanguagesFile string                    `json:"languagesFile"`
-	Languages     map[string]LanguageConfig `json:"languages"`
-	Analyzers     map[string]bool           `json:"analyzers"`
-	Scoring       *ScoringConfig            `json:"scoring"`
+	Root           string                     `json:"root"`
+	MaxDepth       int                        `json:"maxDepth"`
+	Format         string                     `json:"format"`
+	IgnoreDirs     []string                   `json:"ignoreDirs"`
+	IncludeHidden  bool                       `json:"includeHidden"`
+	LanguagesFile  string                     `json:"languagesFile"`
+	Languages      map[string]LanguageConfig  `json:"languages"`
+	CIFile         string                     `json:"ciFile"`
+	CI             map[string]CIConfig        `json:"ci"`
+	VersionsFile   string                     `json:"versionsFile"`
+	FrameworksFile string                     `json:"frameworksFile"`
+	Frameworks     map[string]FrameworkConfig `json:"frameworks"`
+	Analyzers      map[string]bool            `json:"analyzers"`
+	RegistryFile   string                     `json:"registryFile"`
+	Scoring        *ScoringConfig             `json:"scoring"`
+	Profile        string                     `json:"profile"`
+	Profiles       map[string]Profile         `json:"profiles"`
+
+	// scoringPatch is the raw scoring block of a decoded config, which Merge
+	// deep-merges onto the base scoring rather than replacing it.
+	scoringPatch interface{}
+	// present lists the dotted keys a decoded config set, so Merge can apply
+	// zero and false values; source names the layer it came from, and
+	// origins records which layer set each key of a merged config.
+	present []string
+	source  string
+	origins []origin
+	// documents are the later documents of a multi-document YAML layer,
+	// which Merge applies in order after this one.
+	documents []Config
+	// files lists the config files Discover loaded.
+	files []string
 }
 
 func DefaultConfig() Config {
@@ -32,40 +72,89 @@ func DefaultConfig() Config {
 		Format:     "tree",
 		IgnoreDirs: defaultIgnoreDirs(),
 		Languages:  defaultLanguages(),
+		CI:         defaultCISystems(),
+		Frameworks: defaultFrameworks(),
 		Analyzers:  defaultAnalyzerToggles(),
 		Scoring:    DefaultScoringConfig(),
+		Profiles:   defaultProfiles(),
 	}
 }
 
+// Load reads a JSON or YAML config file. .json, .yaml and .yml files are
+// decoded by extension; anything else is taken as JSON when it starts with
+// "{" and as YAML otherwise.
 func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return Config{}, fmt.Errorf("read config: %w", err)
 	}
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	parse := Parse
+	if isYAML(path, data) {
+		parse = ParseYAML
+	}
+	cfg, err := parse(data, path)
+	if err != nil {
 		return Config{}, fmt.Errorf("parse config %s: %w", filepath.Base(path), err)
 	}
 	return cfg, nil
 }
 
+func isYAML(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	case ".json":
+		return false
+	}
+	return !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// UnmarshalJSON decodes a config, noting which keys it sets and keeping its
+// raw scoring block for Merge.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Config(decoded)
+	c.present = presentPaths(raw)
+	for key, value := range raw {
+		if strings.EqualFold(key, "scoring") {
+			c.scoringPatch = value
+		}
+	}
+	return nil
+}
+
+// Merge layers overrides onto base. For a decoded overrides config every key
+// it contains applies, including zero and false values; ignoreDirs, the
+// language, CI and framework tables and analyzer toggles accumulate, and
+// scoring is deep-merged. The later documents of a multi-document YAML layer
+// are merged the same way, one after another.
 func Merge(base Config, overrides Config) Config {
 	merged := base
+	merged.scoringPatch, merged.present, merged.source, merged.documents = nil, nil, "", nil
+	merged.origins = mergeOrigins(base, overrides)
 
-	if overrides.Root != "" {
+	if overrides.has("root", overrides.Root != "") {
 		merged.Root = overrides.Root
 	}
-	if overrides.MaxDepth != 0 {
+	if overrides.has("maxDepth", overrides.MaxDepth != 0) {
 		merged.MaxDepth = overrides.MaxDepth
 	}
-	if overrides.Format != "" {
+	if overrides.has("format", overrides.Format != "") {
 		merged.Format = overrides.Format
 	}
 	if overrides.IgnoreDirs != nil {
 		merged.IgnoreDirs = appendUnique(merged.IgnoreDirs, overrides.IgnoreDirs)
 	}
-	if overrides.IncludeHidden {
-		merged.IncludeHidden = true
+	if overrides.has("includeHidden", overrides.IncludeHidden) {
+		merged.IncludeHidden = overrides.IncludeHidden
 	}
 	if overrides.LanguagesFile != "" {
 		merged.LanguagesFile = overrides.LanguagesFile
@@ -73,6 +162,24 @@ func Merge(base Config, overrides Config) Config {
 	if len(overrides.Languages) > 0 {
 		merged.Languages = MergeLanguageMaps(merged.Languages, overrides.Languages)
 	}
+	if overrides.CIFile != "" {
+		merged.CIFile = overrides.CIFile
+	}
+	if len(overrides.CI) > 0 {
+		merged.CI = MergeCIMaps(merged.CI, overrides.CI)
+	}
+	if overrides.VersionsFile != "" {
+		merged.VersionsFile = overrides.VersionsFile
+	}
+	if overrides.FrameworksFile != "" {
+		merged.FrameworksFile = overrides.FrameworksFile
+	}
+	if overrides.RegistryFile != "" {
+		merged.RegistryFile = overrides.RegistryFile
+	}
+	if len(overrides.Frameworks) > 0 {
+		merged.Frameworks = MergeFrameworkMaps(merged.Frameworks, overrides.Frameworks)
+	}
 	if overrides.Analyzers != nil {
 		if merged.Analyzers == nil {
 			merged.Analyzers = make(map[string]bool)
@@ -81,9 +188,33 @@ func Merge(base Config, overrides Config) Config {
 			merged.Analyzers[strings.ToLower(name)] = enabled
 		}
 	}
-	if overrides.Scoring != nil {
+	if overrides.scoringPatch != nil {
+		// The patch already decoded once as overrides.Scoring, so merging it
+		// cannot introduce a type error; the fallback is only defensive.
+		scoring, err := patchScoring(merged.Scoring, overrides.scoringPatch)
+		if err != nil {
+			scoring = overrides.Scoring
+		}
+		merged.Scoring = scoring
+	} else if overrides.Scoring != nil {
 		merged.Scoring = overrides.Scoring
 	}
+	if overrides.has("profile", overrides.Profile != "") {
+		merged.Profile = overrides.Profile
+	}
+	if len(overrides.Profiles) > 0 {
+		profiles := make(map[string]Profile, len(merged.Profiles)+len(overrides.Profiles))
+		for name, profile := range merged.Profiles {
+			profiles[name] = profile
+		}
+		for name, profile := range overrides.Profiles {
+			profiles[name] = profile
+		}
+		merged.Profiles = profiles
+	}
+	for _, doc := range overrides.documents {
+		merged = Merge(merged, doc)
+	}
 	return merged
 }
 
@@ -112,6 +243,25 @@ func (c Config) AllIgnoreDirs() []string {
 	return out
 }
 
+// ArtifactDirs returns the regenerable build and dependency directories: the
+// union of every language's skipDirs.
+func (c Config) ArtifactDirs() []string {
+	set := make(map[string]struct{})
+	for _, lang := range c.Languages {
+		for _, dir := range lang.SkipDirs {
+			if clean := strings.TrimSpace(dir); clean != "" {
+				set[clean] = struct{}{}
+			}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for dir := range set {
+		out = append(out, dir)
+	}
+	sort.Strings(out)
+	return out
+}
+
 func (c Config) ExtensionMapping() map[string]string {
 	mapping := make(map[string]string)
 	for language, langConfig := range c.Languages {
@@ -130,18 +280,119 @@ func (c Config) ExtensionMapping() map[string]string {
 }
 
 func (c Config) ResolveLanguages() (map[string]LanguageConfig, error) {
+	langs, _, err := c.resolveLanguages()
+	return langs, err
+}
+
+// resolveLanguages also returns the names the languages file defines.
+func (c Config) resolveLanguages() (map[string]LanguageConfig, []string, error) {
 	langs := defaultLanguages()
+	var fromFile []string
 	if c.LanguagesFile != "" {
 		fileLangs, err := LoadLanguagesFile(c.LanguagesFile)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		langs = MergeLanguageMaps(langs, fileLangs)
+		for name := range fileLangs {
+			fromFile = append(fromFile, name)
+		}
 	}
 	if len(c.Languages) > 0 {
 		langs = MergeLanguageMaps(langs, c.Languages)
 	}
-	return langs, nil
+	return langs, fromFile, nil
+}
+
+func (c Config) ResolveCI() (map[string]CIConfig, error) {
+	systems, _, err := c.resolveCI()
+	return systems, err
+}
+
+func (c Config) resolveCI() (map[string]CIConfig, []string, error) {
+	systems := defaultCISystems()
+	var fromFile []string
+	if c.CIFile != "" {
+		fileSystems, err := LoadCIFile(c.CIFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		systems = MergeCIMaps(systems, fileSystems)
+		for name := range fileSystems {
+			fromFile = append(fromFile, name)
+		}
+	}
+	if len(c.CI) > 0 {
+		systems = MergeCIMaps(systems, c.CI)
+	}
+	return systems, fromFile, nil
+}
+
+func (c Config) ResolveFrameworks() (map[string]FrameworkConfig, error) {
+	frameworks, _, err := c.resolveFrameworks()
+	return frameworks, err
+}
+
+func (c Config) resolveFrameworks() (map[string]FrameworkConfig, []string, error) {
+	frameworks := defaultFrameworks()
+	var fromFile []string
+	if c.FrameworksFile != "" {
+		fileFrameworks, err := LoadFrameworksFile(c.FrameworksFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		frameworks = MergeFrameworkMaps(frameworks, fileFrameworks)
+		for name := range fileFrameworks {
+			fromFile = append(fromFile, name)
+		}
+	}
+	if len(c.Frameworks) > 0 {
+		frameworks = MergeFrameworkMaps(frameworks, c.Frameworks)
+	}
+	return frameworks, fromFile, nil
+}
+
+// ResolveTables replaces Languages, CI and Frameworks with the defaults
+// merged with the configured files and inline entries, recording each file
+// as an origin of the entries it defines.
+func (c *Config) ResolveTables() error {
+	langs, langNames, err := c.resolveLanguages()
+	if err != nil {
+		return fmt.Errorf("load languages: %w", err)
+	}
+	ciSystems, ciNames, err := c.resolveCI()
+	if err != nil {
+		return fmt.Errorf("load ci markers: %w", err)
+	}
+	frameworks, frameworkNames, err := c.resolveFrameworks()
+	if err != nil {
+		return fmt.Errorf("load frameworks: %w", err)
+	}
+	c.recordFile("languages", c.LanguagesFile, langNames)
+	c.recordFile("ci", c.CIFile, ciNames)
+	c.recordFile("frameworks", c.FrameworksFile, frameworkNames)
+	c.Languages, c.CI, c.Frameworks = langs, ciSystems, frameworks
+	return nil
+}
+
+func (c *Config) recordFile(table, file string, names []string) {
+	for _, name := range names {
+		c.origins = append(c.origins, origin{path: table + "." + name, source: file})
+	}
+}
+
+func (c Config) CIMarkers() map[string][]string {
+	markers := make(map[string][]string, len(c.CI))
+	for system, ciConfig := range c.CI {
+		for _, marker := range ciConfig.Markers {
+			clean := strings.TrimSpace(marker)
+			if clean == "" {
+				continue
+			}
+			markers[system] = append(markers[system], clean)
+		}
+	}
+	return markers
 }
 
 func (c Config) EffectiveAnalyzers() map[string]bool {
@@ -164,22 +415,73 @@ func defaultAnalyzerToggles() map[string]bool {
 }
 
 type ScoringConfig struct {
-	Effort     EffortConfig   `json:"effort"`
-	Polish     PolishConfig   `json:"polish"`
-	Recency    []AgeThreshold `json:"recency"`
-	Categories CategoryConfig `json:"categories"`
+	Effort     EffortConfig      `json:"effort"`
+	Polish     PolishConfig      `json:"polish"`
+	Recency    RecencyConfig     `json:"recency"`
+	Categories CategoryConfig    `json:"categories"`
+	Custom     []CustomComponent `json:"custom,omitempty"`
+}
+
+// CustomComponent awards Points to Score (effort, polish or recency) when the
+// When expression holds for a project's metrics.
+type CustomComponent struct {
+	Name   string `json:"name"`
+	Score  string `json:"score"`
+	When   string `json:"when"`
+	Points int    `json:"points"`
 }
 
 type EffortConfig struct {
 	Commit []RangeThreshold `json:"commit"`
 	Active []RangeThreshold `json:"active"`
+	// CommitCurve, when set, replaces the Commit thresholds.
+	CommitCurve *Curve `json:"commitCurve,omitempty"`
+}
+
+// RecencyConfig awards points by days since a project was last touched,
+// either through step thresholds or, when Curve is set, a continuous curve.
+type RecencyConfig struct {
+	Thresholds []AgeThreshold `json:"thresholds,omitempty"`
+	Curve      *Curve         `json:"curve,omitempty"`
+}
+
+// UnmarshalJSON also accepts the legacy form, a bare list of thresholds.
+func (r *RecencyConfig) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		*r = RecencyConfig{}
+		return json.Unmarshal(data, &r.Thresholds)
+	}
+	type plain RecencyConfig
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = RecencyConfig(decoded)
+	return nil
+}
+
+// Curve maps a value continuously to points, up to Points. Recency curves
+// are linear (reaching zero at MaxDays), exponential (halving every HalfLife
+// days) or logistic (half of Points at Midpoint, falling off with Steepness
+// per day). Effort curves are logarithmic, reaching Points at Saturation.
+type Curve struct {
+	Type       string  `json:"type"`
+	Points     float64 `json:"points"`
+	MaxDays    float64 `json:"maxDays,omitempty"`
+	HalfLife   float64 `json:"halfLife,omitempty"`
+	Midpoint   float64 `json:"midpoint,omitempty"`
+	Steepness  float64 `json:"steepness,omitempty"`
+	Saturation float64 `json:"saturation,omitempty"`
 }
 
 type PolishConfig struct {
-	Readme int `json:"readme"`
-	Tests  int `json:"tests"`
-	CI     int `json:"ci"`
-	Docker int `json:"docker"`
+	Readme    int              `json:"readme"`
+	Tests     int              `json:"tests"`
+	CI        int              `json:"ci"`
+	Docker    int              `json:"docker"`
+	License   int              `json:"license"`
+	TestRatio []RatioThreshold `json:"testRatio"`
 }
 
 type RangeThreshold struct {
@@ -187,26 +489,98 @@ type RangeThreshold struct {
 	Points int `json:"points"`
 }
 
+type RatioThreshold struct {
+	Min    float64 `json:"min"`
+	Points int     `json:"points"`
+}
+
 type AgeThreshold struct {
 	MaxDays int `json:"maxDays"`
 	Points  int `json:"points"`
 }
 
-type CategoryConfig struct {
-	Experiment CategoryRule `json:"experiment"`
-	Prototype  CategoryRule `json:"prototype"`
-	Archived   CategoryRule `json:"archived"`
-	Product    CategoryRule `json:"product"`
-}
+// CategoryConfig is the ordered list of category rules. A project gets the
+// name of the first rule whose bounds it satisfies, so a rule without bounds
+// acts as the fallback.
+type CategoryConfig []CategoryRule
 
 type CategoryRule struct {
-	CommitMax  *int `json:"commitMax"`
-	EffortMax  *int `json:"effortMax"`
-	EffortMin  *int `json:"effortMin"`
-	PolishMax  *int `json:"polishMax"`
-	PolishMin  *int `json:"polishMin"`
-	RecencyMax *int `json:"recencyMax"`
-	RecencyMin *int `json:"recencyMin"`
+	Name       string `json:"name"`
+	Color      string `json:"color,omitempty"`
+	CommitMax  *int   `json:"commitMax,omitempty"`
+	EffortMax  *int   `json:"effortMax,omitempty"`
+	EffortMin  *int   `json:"effortMin,omitempty"`
+	PolishMax  *int   `json:"polishMax,omitempty"`
+	PolishMin  *int   `json:"polishMin,omitempty"`
+	RecencyMax *int   `json:"recencyMax,omitempty"`
+	RecencyMin *int   `json:"recencyMin,omitempty"`
+	// When is an optional expression that must also hold; see package expr.
+	When string `json:"when,omitempty"`
+}
+
+// legacyCategories maps the keys of the old fixed-field categories object to
+// category names, in their original order of precedence.
+var legacyCategories = []struct{ key, name string }{
+	{"experiment", "Experiment"},
+	{"prototype", "Prototype"},
+	{"archived", "Archived"},
+	{"product", "Product-ish"},
+}
+
+// UnmarshalJSON accepts either a list of rules or the legacy object keyed by
+// experiment/prototype/archived/product. Legacy objects keep their fixed order
+// and fall back to "Serious"; any other keys follow in alphabetical order.
+func (c *CategoryConfig) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		var rules []CategoryRule
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return err
+		}
+		*c = rules
+		return nil
+	}
+
+	var legacy map[string]*CategoryRule
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	var rules CategoryConfig
+	for _, entry := range legacyCategories {
+		if rule, ok := legacy[entry.key]; ok && rule != nil {
+			rule.Name = entry.name
+			rules = append(rules, *rule)
+		}
+		delete(legacy, entry.key)
+	}
+	extra := make([]string, 0, len(legacy))
+	for key := range legacy {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		rule := legacy[key]
+		if rule == nil {
+			continue
+		}
+		if rule.Name == "" {
+			rule.Name = key
+		}
+		rules = append(rules, *rule)
+	}
+	*c = append(rules, CategoryRule{Name: "Serious"})
+	return nil
+}
+
+// Colors maps category names to their configured colors.
+func (c CategoryConfig) Colors() map[string]string {
+	colors := make(map[string]string, len(c))
+	for _, rule := range c {
+		if rule.Color != "" {
+			colors[rule.Name] = rule.Color
+		}
+	}
+	return colors
 }
 
 func defaultLanguages() map[string]LanguageConfig {
@@ -240,8 +614,11 @@ func MergeLanguageMaps(base, overrides map[string]LanguageConfig) map[string]Lan
 	for name, lang := range overrides {
 		if existing, ok := result[name]; ok {
 			result[name] = LanguageConfig{
-				Extensions: appendUnique(existing.Extensions, lang.Extensions),
-				SkipDirs:   appendUnique(existing.SkipDirs, lang.SkipDirs),
+				Extensions:  appendUnique(existing.Extensions, lang.Extensions),
+				SkipDirs:    appendUnique(existing.SkipDirs, lang.SkipDirs),
+				TestFiles:   appendUnique(existing.TestFiles, lang.TestFiles),
+				TestDirs:    appendUnique(existing.TestDirs, lang.TestDirs),
+				TestMarkers: appendUnique(existing.TestMarkers, lang.TestMarkers),
 			}
 		} else {
 			result[name] = lang
@@ -250,6 +627,90 @@ func MergeLanguageMaps(base, overrides map[string]LanguageConfig) map[string]Lan
 	return result
 }
 
+func defaultCISystems() map[string]CIConfig {
+	var systems map[string]CIConfig
+	if err := decodeYAML(defaultCIYAML, &systems); err != nil {
+		panic(fmt.Sprintf("invalid default ci yaml: %v", err))
+	}
+	return systems
+}
+
+func LoadCIFile(path string) (map[string]CIConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ci file: %w", err)
+	}
+	var systems map[string]CIConfig
+	if err := decodeYAML(data, &systems); err != nil {
+		return nil, err
+	}
+	return systems, nil
+}
+
+func MergeCIMaps(base, overrides map[string]CIConfig) map[string]CIConfig {
+	if base == nil && overrides == nil {
+		return nil
+	}
+	result := make(map[string]CIConfig)
+	for name, system := range base {
+		result[name] = system
+	}
+	for name, system := range overrides {
+		if existing, ok := result[name]; ok {
+			result[name] = CIConfig{Markers: appendUnique(existing.Markers, system.Markers)}
+		} else {
+			result[name] = system
+		}
+	}
+	return result
+}
+
+func defaultFrameworks() map[string]FrameworkConfig {
+	var frameworks map[string]FrameworkConfig
+	if err := decodeYAML(defaultFrameworksYAML, &frameworks); err != nil {
+		panic(fmt.Sprintf("invalid default frameworks yaml: %v", err))
+	}
+	return frameworks
+}
+
+func LoadFrameworksFile(path string) (map[string]FrameworkConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read frameworks file: %w", err)
+	}
+	var frameworks map[string]FrameworkConfig
+	if err := decodeYAML(data, &frameworks); err != nil {
+		return nil, err
+	}
+	return frameworks, nil
+}
+
+func MergeFrameworkMaps(base, overrides map[string]FrameworkConfig) map[string]FrameworkConfig {
+	if base == nil && overrides == nil {
+		return nil
+	}
+	result := make(map[string]FrameworkConfig)
+	for name, fw := range base {
+		result[name] = fw
+	}
+	for name, fw := range overrides {
+		existing, ok := result[name]
+		if !ok {
+			result[name] = fw
+			continue
+		}
+		if fw.Type != "" {
+			existing.Type = fw.Type
+		}
+		existing.Dependencies = appendUnique(existing.Dependencies, fw.Dependencies)
+		existing.Files = appendUnique(existing.Files, fw.Files)
+		existing.Imports = appendUnique(existing.Imports, fw.Imports)
+		existing.Extensions = appendUnique(existing.Extensions, fw.Extensions)
+		result[name] = existing
+	}
+	return result
+}
+
 func appendUnique(base []string, more []string) []string {
 	if len(more) == 0 {
 		return base
@@ -1,6 +1,14 @@
 package config
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestEffectiveAnalyzers(t *testing.T) {
 	cfg := DefaultConfig()
@@ -20,3 +28,552 @@ func TestEffectiveAnalyzers(t *testing.T) {
 		t.Fatalf("expected fs analyzer to remain enabled by default")
 	}
 }
+
+func TestResolveCIMergesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yaml")
+	data := []byte(`
+Concourse:
+  markers:
+    - ci/pipeline.yml
+Jenkins:
+  markers:
+    - ci/Jenkinsfile
+`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write ci file: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.CIFile = path
+	systems, err := cfg.ResolveCI()
+	if err != nil {
+		t.Fatalf("ResolveCI error: %v", err)
+	}
+	if _, ok := systems["Concourse"]; !ok {
+		t.Fatalf("expected custom CI system to be added")
+	}
+	if _, ok := systems["Travis CI"]; !ok {
+		t.Fatalf("expected embedded CI systems to be kept")
+	}
+	if got := systems["Jenkins"].Markers; len(got) != 2 {
+		t.Fatalf("expected Jenkins markers to be merged, got %v", got)
+	}
+}
+
+func TestLoadVersionsFileAcceptsNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "versions.yaml")
+	data := []byte(`
+toolchains:
+  go: "1.23.0"
+  node: 22
+  golang: 1.20
+  python: 3.10
+packages:
+  npm:
+    react: 18.3.1
+`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write versions file: %v", err)
+	}
+	table, err := LoadVersionsFile(path)
+	if err != nil {
+		t.Fatalf("LoadVersionsFile error: %v", err)
+	}
+	if table.Toolchains["node"] != "22" || table.Toolchains["go"] != "1.23.0" ||
+		table.Toolchains["golang"] != "1.20" || table.Toolchains["python"] != "3.10" {
+		t.Fatalf("unexpected toolchains: %v", table.Toolchains)
+	}
+	if table.Packages["npm"]["react"] != "18.3.1" {
+		t.Fatalf("unexpected packages: %v", table.Packages)
+	}
+}
+
+func TestCategoryConfigDecoding(t *testing.T) {
+	var ordered ScoringConfig
+	if err := json.Unmarshal([]byte(`{"categories": [
+		{"name": "Client Work", "color": "magenta", "effortMin": 5},
+		{"name": "Abandoned", "recencyMax": 0}
+	]}`), &ordered); err != nil {
+		t.Fatalf("decode ordered categories: %v", err)
+	}
+	if len(ordered.Categories) != 2 || ordered.Categories[0].Name != "Client Work" || ordered.Categories[1].Name != "Abandoned" {
+		t.Fatalf("unexpected categories: %+v", ordered.Categories)
+	}
+	if colors := ordered.Categories.Colors(); colors["Client Work"] != "magenta" || len(colors) != 1 {
+		t.Fatalf("unexpected colors: %v", colors)
+	}
+
+	var legacy ScoringConfig
+	if err := json.Unmarshal([]byte(`{"categories": {"product": {"polishMin": 10}, "experiment": {"commitMax": 2}}}`), &legacy); err != nil {
+		t.Fatalf("decode legacy categories: %v", err)
+	}
+	var names []string
+	for _, rule := range legacy.Categories {
+		names = append(names, rule.Name)
+	}
+	if want := []string{"Experiment", "Product-ish", "Serious"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	if rule := legacy.Categories[1]; rule.PolishMin == nil || *rule.PolishMin != 10 {
+		t.Fatalf("expected product polishMin 10, got %+v", rule)
+	}
+}
+
+func TestRecencyConfigAcceptsLegacyList(t *testing.T) {
+	var sc ScoringConfig
+	if err := json.Unmarshal([]byte(`{"recency": [{"maxDays": 90, "points": 6}]}`), &sc); err != nil {
+		t.Fatalf("decode legacy recency: %v", err)
+	}
+	if len(sc.Recency.Thresholds) != 1 || sc.Recency.Thresholds[0].Points != 6 || sc.Recency.Curve != nil {
+		t.Fatalf("unexpected recency config: %+v", sc.Recency)
+	}
+
+	if len(DefaultScoringConfig().Recency.Thresholds) == 0 {
+		t.Fatalf("expected default recency thresholds")
+	}
+}
+
+func TestScoringProfiles(t *testing.T) {
+	var fileCfg Config
+	if err := json.Unmarshal([]byte(`{"profiles": {
+		"mine": {"base": "showcase", "polish": {"docker": 5}},
+		"loop-a": {"base": "loop-b"},
+		"loop-b": {"base": "loop-a"}
+	}}`), &fileCfg); err != nil {
+		t.Fatalf("decode profiles: %v", err)
+	}
+	cfg := Merge(DefaultConfig(), fileCfg)
+
+	sc, err := cfg.ScoringProfile("mine")
+	if err != nil {
+		t.Fatalf("ScoringProfile returned error: %v", err)
+	}
+	if sc.Polish.Docker != 5 || sc.Polish.Tests != 4 || sc.Polish.Readme != 3 {
+		t.Fatalf("expected mine to override docker on top of showcase, got %+v", sc.Polish)
+	}
+	if len(sc.Effort.Commit) == 0 || len(sc.Polish.TestRatio) == 0 {
+		t.Fatalf("expected untouched sections to keep their defaults, got %+v", sc)
+	}
+	if sc.Categories[0].Name != "Showcase" {
+		t.Fatalf("expected showcase categories, got %+v", sc.Categories)
+	}
+	if cfg.Scoring.Polish.Docker != 2 {
+		t.Fatalf("resolving a profile modified the base scoring config")
+	}
+
+	for _, name := range []string{"default", "showcase", "cleanup", "risk"} {
+		if _, err := cfg.ScoringProfile(name); err != nil {
+			t.Fatalf("profile %s: %v", name, err)
+		}
+	}
+	if _, err := cfg.ScoringProfile("loop-a"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected an inheritance cycle error, got %v", err)
+	}
+	if _, err := cfg.ScoringProfile("nope"); err == nil || !strings.Contains(err.Error(), "available: default, cleanup") {
+		t.Fatalf("expected an unknown profile error listing profiles, got %v", err)
+	}
+}
+
+func TestMergeDeepMergesScoring(t *testing.T) {
+	decode := func(src string) Config {
+		t.Helper()
+		var cfg Config
+		if err := json.Unmarshal([]byte(src), &cfg); err != nil {
+			t.Fatalf("decode %s: %v", src, err)
+		}
+		return cfg
+	}
+	defaults := DefaultConfig()
+
+	merged := Merge(DefaultConfig(), decode(`{"scoring": {"polish": {"readme": 3, "docker": 1}}}`))
+	sc := merged.Scoring
+	if sc.Polish.Readme != 3 || sc.Polish.Docker != 1 || sc.Polish.Tests != defaults.Scoring.Polish.Tests {
+		t.Fatalf("expected readme and docker overridden and tests kept, got %+v", sc.Polish)
+	}
+	if !reflect.DeepEqual(sc.Effort, defaults.Scoring.Effort) || !reflect.DeepEqual(sc.Recency, defaults.Scoring.Recency) {
+		t.Fatalf("expected effort and recency defaults to survive a polish-only override")
+	}
+	if len(sc.Categories) != len(defaults.Scoring.Categories) {
+		t.Fatalf("expected default categories to survive, got %+v", sc.Categories)
+	}
+
+	merged = Merge(DefaultConfig(), decode(`{"scoring": {
+		"polish": {"testRatio": []},
+		"effort": {"commit": [{"min": 50, "points": 20}]}
+	}}`))
+	if len(merged.Scoring.Polish.TestRatio) != 0 {
+		t.Fatalf("expected [] to clear testRatio, got %+v", merged.Scoring.Polish.TestRatio)
+	}
+	if len(merged.Scoring.Effort.Commit) != 1 || len(merged.Scoring.Effort.Active) == 0 {
+		t.Fatalf("expected commit replaced and active kept, got %+v", merged.Scoring.Effort)
+	}
+
+	merged = Merge(DefaultConfig(), decode(`{"scoring": {"categories": {
+		"product": {"polishMin": 12},
+		"archived": {"effortMin": null},
+		"prototype": null,
+		"client": {"name": "Client Work", "when": "hasCI"}
+	}}}`))
+	var names []string
+	for _, rule := range merged.Scoring.Categories {
+		names = append(names, rule.Name)
+	}
+	if want := []string{"Experiment", "Archived", "Product-ish", "Client Work", "Serious"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+	archived, product := merged.Scoring.Categories[1], merged.Scoring.Categories[2]
+	if archived.EffortMin != nil || archived.RecencyMax == nil {
+		t.Fatalf("expected null to drop only archived.effortMin, got %+v", archived)
+	}
+	if *product.PolishMin != 12 || product.EffortMin == nil || product.Color != "green" {
+		t.Fatalf("expected product-ish patched in place, got %+v", product)
+	}
+
+	merged = Merge(DefaultConfig(), decode(`{"scoring": {"categories": [{"name": "Everything"}]}}`))
+	if len(merged.Scoring.Categories) != 1 {
+		t.Fatalf("expected a category list to replace the defaults, got %+v", merged.Scoring.Categories)
+	}
+}
+
+func TestMergeAppliesZeroValuesAndTracksOrigins(t *testing.T) {
+	file, err := Parse([]byte(`{"maxDepth": 3, "includeHidden": true, "ignoreDirs": ["tmp"]}`), "team.json")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	flags, err := FromMap(map[string]interface{}{"maxDepth": 0, "includeHidden": false, "profile": "risk"}, "flags")
+	if err != nil {
+		t.Fatalf("FromMap returned error: %v", err)
+	}
+	cfg := Merge(Merge(DefaultConfig(), file), flags)
+	if cfg.MaxDepth != 0 || cfg.IncludeHidden {
+		t.Fatalf("expected flags to reset maxDepth and includeHidden, got %d, %v", cfg.MaxDepth, cfg.IncludeHidden)
+	}
+	if err := cfg.ApplyProfile(); err != nil {
+		t.Fatalf("ApplyProfile returned error: %v", err)
+	}
+
+	origins := map[string]string{}
+	for _, setting := range cfg.Settings() {
+		origins[setting.Key] = setting.Origin
+	}
+	want := map[string]string{
+		"maxDepth":              "flags",
+		"includeHidden":         "flags",
+		"format":                "defaults",
+		"ignoreDirs":            "defaults + team.json",
+		"scoring.polish.tests":  "profile risk",
+		"scoring.polish.readme": "defaults",
+	}
+	for key, origin := range want {
+		if origins[key] != origin {
+			t.Errorf("origin of %s = %q, want %q", key, origins[key], origin)
+		}
+	}
+
+	// Configs built in code keep the old rule: only non-zero values apply.
+	if merged := Merge(file, Config{Format: "json"}); merged.MaxDepth != 3 || !merged.IncludeHidden {
+		t.Fatalf("expected zero values of an in-code config to be ignored, got %+v", merged)
+	}
+}
+
+func TestDiscoverLayersSources(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	userDir := filepath.Join(home, ".config", "proj-audit")
+	if err := os.MkdirAll(userDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "config.json"), []byte(`{"maxDepth": 2, "format": "markdown", "profile": "cleanup"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(home, "code")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rootFile := "root: /elsewhere\nmaxDepth: 4\nincludeHidden: true\ncategory: tooling\n"
+	if err := os.WriteFile(filepath.Join(root, ".proj-audit.yaml"), []byte(rootFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	flags, err := FromMap(map[string]interface{}{"root": "~/code"}, "flags")
+	if err != nil {
+		t.Fatalf("FromMap returned error: %v", err)
+	}
+	cfg, err := Discover(Sources{
+		Environ: []string{"PROJ_AUDIT_FORMAT=json", "PROJ_AUDIT_IGNORE_DIRS=tmp, out", "PROJ_AUDIT_REGISTRY_FILE=~/registry.json", "OTHER=1"},
+		Flags:   flags,
+	})
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+
+	if cfg.Root != root {
+		t.Errorf("root = %q, want %q", cfg.Root, root)
+	}
+	if cfg.MaxDepth != 4 || !cfg.IncludeHidden {
+		t.Errorf("expected the root file to override the user config, got maxDepth %d, includeHidden %v", cfg.MaxDepth, cfg.IncludeHidden)
+	}
+	if cfg.Format != "json" || cfg.Profile != "cleanup" {
+		t.Errorf("format, profile = %q, %q, want json, cleanup", cfg.Format, cfg.Profile)
+	}
+	if cfg.RegistryFile != filepath.Join(home, "registry.json") {
+		t.Errorf("registryFile = %q, want ~ expanded", cfg.RegistryFile)
+	}
+	if !contains(cfg.IgnoreDirs, "tmp") || !contains(cfg.IgnoreDirs, "out") {
+		t.Errorf("expected ignoreDirs from the environment, got %v", cfg.IgnoreDirs)
+	}
+	if origin := cfg.Origin("maxDepth"); origin != filepath.Join(root, ".proj-audit.yaml") {
+		t.Errorf("origin of maxDepth = %q", origin)
+	}
+	if origin := cfg.Origin("format"); origin != "env" {
+		t.Errorf("origin of format = %q, want env", origin)
+	}
+
+	if _, err := Discover(Sources{Environ: []string{"PROJ_AUDIT_MAX_DEPTH=deep"}}); err == nil {
+		t.Fatalf("expected an error for a malformed PROJ_AUDIT_MAX_DEPTH")
+	}
+}
+
+func TestLoadAcceptsYAML(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "team.json")
+	jsonConfig := `{
+  "maxDepth": 3,
+  "ignoreDirs": ["tmp"],
+  "analyzers": {"git": false},
+  "scoring": {
+    "polish": {"docker": null},
+    "categories": [{"name": "Showcase", "color": "green", "polishMin": 10}, {"name": "Serious"}]
+  }
+}`
+	yamlConfig := `# same as team.json
+maxDepth: 3
+ignoreDirs:
+  - tmp
+analyzers:
+  git: false
+scoring:
+  polish:
+    docker: null
+  categories:
+    - name: Showcase
+      color: green
+      polishMin: 10
+    - name: Serious
+`
+	files := map[string]string{jsonPath: jsonConfig, filepath.Join(dir, "team.yaml"): yamlConfig, filepath.Join(dir, "teamrc"): yamlConfig}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fromJSON, err := Load(jsonPath)
+	if err != nil {
+		t.Fatalf("Load(json) returned error: %v", err)
+	}
+	want := Merge(DefaultConfig(), fromJSON)
+	for _, name := range []string{"team.yaml", "teamrc"} {
+		cfg, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Load(%s) returned error: %v", name, err)
+		}
+		got := Merge(DefaultConfig(), cfg)
+		if !reflect.DeepEqual(got.Scoring, want.Scoring) || !reflect.DeepEqual(got.IgnoreDirs, want.IgnoreDirs) ||
+			!reflect.DeepEqual(got.Analyzers, want.Analyzers) || got.MaxDepth != want.MaxDepth {
+			t.Fatalf("%s decoded differently from the JSON config", name)
+		}
+	}
+}
+
+func TestLoadMergesYAMLDocumentsAsLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team.yaml")
+	stream := `maxDepth: 3
+includeHidden: true
+ignoreDirs: [tmp]
+scoring:
+  polish:
+    docker: null
+---
+# later documents override earlier keys
+includeHidden: false
+ignoreDirs: [cache]
+scoring:
+  polish:
+    readme: 7
+`
+	if err := os.WriteFile(path, []byte(stream), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	got := Merge(DefaultConfig(), cfg)
+
+	first, err := Parse([]byte(`{"maxDepth": 3, "includeHidden": true, "ignoreDirs": ["tmp"], "scoring": {"polish": {"docker": null}}}`), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Parse([]byte(`{"includeHidden": false, "ignoreDirs": ["cache"], "scoring": {"polish": {"readme": 7}}}`), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Merge(Merge(DefaultConfig(), first), second)
+	if !reflect.DeepEqual(got.Scoring, want.Scoring) || !reflect.DeepEqual(got.IgnoreDirs, want.IgnoreDirs) ||
+		got.MaxDepth != 3 || got.IncludeHidden {
+		t.Fatalf("documents were not merged as layers: maxDepth %d, includeHidden %v, ignoreDirs %v", got.MaxDepth, got.IncludeHidden, got.IgnoreDirs)
+	}
+	if got.Scoring.Polish.Readme != 7 || got.Scoring.Polish.Docker != 0 {
+		t.Fatalf("unexpected polish weights %+v", got.Scoring.Polish)
+	}
+	if origin := got.Origin("includeHidden"); origin != path {
+		t.Fatalf("origin of includeHidden = %q, want %q", origin, path)
+	}
+}
+
+func TestValidateReportsProblemsWithPositions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "team.yaml")
+	yamlConfig := `scoring:
+  polsh:
+    readme: 2
+  effort:
+    commit:
+      - min: 5
+        points: 1
+      - min: 50
+        points: 4
+  categories:
+    - name: Anything
+    - name: Big
+      effortMin: 10
+      commitmax: 3
+analyzers:
+  gti: false
+`
+	jsonPath := filepath.Join(dir, "team.json")
+	jsonConfig := `{
+  "scoring": {"recency": {"thresholds": [
+    {"maxDays": 100, "points": 1},
+    {"maxDays": 10, "points": 3}
+  ]}}
+}`
+	if err := os.WriteFile(yamlPath, []byte(yamlConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(jsonConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(sc *ScoringConfig) error {
+		if len(sc.Categories) == 0 || sc.Categories[0].Name != "Anything" {
+			return nil
+		}
+		return &KeyError{Key: "categories[0]", Err: errors.New("checked")}
+	}
+	for path, want := range map[string][]string{
+		yamlPath: {
+			yamlPath + ":2:3: scoring.polsh: unknown key \"polsh\" (did you mean \"polish\"?)",
+			yamlPath + ":8:7: scoring.effort.commit[1]: min 50 is not below the previous threshold's min 5;",
+			yamlPath + ":11:5: scoring.categories[0]: checked",
+			yamlPath + ":12:5: scoring.categories[1]: category \"Big\" never matches: it overlaps \"Anything\"",
+			yamlPath + ":14:7: scoring.categories[1].commitmax: unknown key \"commitmax\" (did you mean \"commitMax\"?)",
+			yamlPath + ":16:3: analyzers.gti: unknown analyzer \"gti\" (did you mean \"git\"?)",
+		},
+		jsonPath: {
+			jsonPath + ":4:5: scoring.recency.thresholds[1]: maxDays 10 is not above the previous threshold's maxDays 100;",
+		},
+	} {
+		cfg, err := Discover(Sources{File: path})
+		if err != nil {
+			t.Fatalf("Discover(%s) returned error: %v", path, err)
+		}
+		problems := cfg.Validate(check)
+		if len(problems) != len(want) {
+			t.Fatalf("%s: got %d problems, want %d: %v", path, len(problems), len(want), problems)
+		}
+		for i, problem := range problems {
+			if !strings.HasPrefix(problem.String(), want[i]) {
+				t.Errorf("problem %d = %q, want prefix %q", i, problem, want[i])
+			}
+		}
+	}
+
+	cfg, err := Discover(Sources{})
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+	if problems := cfg.Validate(); len(problems) != 0 {
+		t.Fatalf("expected the defaults to validate, got %v", problems)
+	}
+}
+
+func TestStarterReadsBackAsTheDefaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	defaults := DefaultConfig()
+	dir := t.TempDir()
+	for name, opts := range map[string]StarterOptions{
+		"plain": {},
+		"tuned": {Root: dir, Languages: []string{"Go"}, IgnoreDirs: []string{"coverage", "vendor"}},
+	} {
+		data := Starter(opts)
+		cfg, err := ParseYAML(data, name)
+		if err != nil {
+			t.Fatalf("%s: ParseYAML returned error: %v\n%s", name, err, data)
+		}
+		merged := Merge(DefaultConfig(), cfg)
+		if !reflect.DeepEqual(merged.Scoring, defaults.Scoring) {
+			t.Errorf("%s: scoring = %+v, want the defaults", name, merged.Scoring)
+		}
+		if !reflect.DeepEqual(cfg.Analyzers, defaults.Analyzers) {
+			t.Errorf("%s: analyzers = %v, want %v", name, cfg.Analyzers, defaults.Analyzers)
+		}
+		if merged.MaxDepth != defaults.MaxDepth || merged.Format != defaults.Format || merged.IncludeHidden {
+			t.Errorf("%s: got maxDepth %d, format %q, includeHidden %t", name, merged.MaxDepth, merged.Format, merged.IncludeHidden)
+		}
+
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		discovered, err := Discover(Sources{File: path})
+		if err != nil {
+			t.Fatalf("%s: Discover returned error: %v", name, err)
+		}
+		if problems := discovered.Validate(); len(problems) != 0 {
+			t.Errorf("%s: expected the starter config to validate, got %v", name, problems)
+		}
+	}
+
+	plain, err := ParseYAML(Starter(StarterOptions{}), "plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plain.Languages, defaults.Languages) {
+		t.Errorf("languages = %v, want every built-in language", plain.Languages)
+	}
+
+	tuned, err := ParseYAML(Starter(StarterOptions{Root: dir, Languages: []string{"Go"}, IgnoreDirs: []string{"coverage"}}), "tuned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tuned.Languages) != 1 || !reflect.DeepEqual(tuned.Languages["Go"], defaults.Languages["Go"]) {
+		t.Errorf("languages = %v, want only Go", tuned.Languages)
+	}
+	if want := append(defaultIgnoreDirs(), "coverage"); !reflect.DeepEqual(tuned.IgnoreDirs, want) {
+		t.Errorf("ignoreDirs = %v, want %v", tuned.IgnoreDirs, want)
+	}
+}
+
+func TestDiscoverReportsUnusableConfigDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "relative/config")
+	if _, err := os.UserConfigDir(); err == nil {
+		t.Skip("XDG_CONFIG_HOME is not used on this platform")
+	}
+	if _, err := Discover(Sources{}); err == nil || !strings.Contains(err.Error(), "user config") {
+		t.Fatalf("expected a user config error for a relative XDG_CONFIG_HOME, got %v", err)
+	}
+}
@@ -11,4 +11,13 @@ var (
 
 	//go:embed scoring.yaml
 	defaultScoringYAML []byte
+
+	//go:embed profiles.yaml
+	defaultProfilesYAML []byte
+
+	//go:embed ci.yaml
+	defaultCIYAML []byte
+
+	//go:embed frameworks.yaml
+	defaultFrameworksYAML []byte
 )
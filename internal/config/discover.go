@@ -0,0 +1,277 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix starts the environment variables that set config keys:
+// PROJ_AUDIT_MAX_DEPTH sets maxDepth, PROJ_AUDIT_IGNORE_DIRS (comma-separated)
+// sets ignoreDirs, and so on. PROJ_AUDIT_CONFIG names a config file, like
+// --config.
+const EnvPrefix = "PROJ_AUDIT_"
+
+// UserConfigFiles are looked for under proj-audit in the user config
+// directory ($XDG_CONFIG_HOME or ~/.config on Linux); the first one found is
+// loaded.
+var UserConfigFiles = []string{"config.json", "config.yaml", "config.yml"}
+
+// Sources are the inputs to Discover besides the files it finds itself.
+type Sources struct {
+	// File is an explicit config file, as given with --config.
+	File string
+	// Environ holds KEY=value pairs, as returned by os.Environ.
+	Environ []string
+	// Flags is the command-line layer.
+	Flags Config
+}
+
+// Discover layers, lowest precedence first: the embedded defaults, the user
+// config file, the .proj-audit.yaml at the scan root, the explicit config
+// file, PROJ_AUDIT_* environment variables and the flags. A leading ~ in
+// root and the file paths is expanded to the home directory.
+func Discover(src Sources) (Config, error) {
+	env, envFile, err := envLayer(src.Environ)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var before, after []Config
+	user, found, err := loadUserConfig()
+	if err != nil {
+		return Config{}, err
+	}
+	if found {
+		before = append(before, user)
+	}
+	file := src.File
+	if file == "" {
+		file = envFile
+	}
+	if file != "" {
+		explicit, err := Load(ExpandHome(file))
+		if err != nil {
+			return Config{}, err
+		}
+		after = append(after, explicit)
+	}
+	after = append(after, env, src.Flags)
+
+	// The scan root decides which root file applies, so it is resolved from
+	// every other layer first.
+	root := mergeAll(DefaultConfig(), append(before, after...)).Root
+	rootCfg, found, err := loadRootFile(ExpandHome(root))
+	if err != nil {
+		return Config{}, err
+	}
+	if found {
+		before = append(before, rootCfg)
+	}
+
+	layers := append(before, after...)
+	cfg := mergeAll(DefaultConfig(), layers)
+	cfg.expandPaths()
+	// Every layer but the environment and the flags is a file.
+	for _, layer := range layers[:len(layers)-2] {
+		cfg.files = append(cfg.files, layer.source)
+	}
+	return cfg, nil
+}
+
+// Files lists the config files Discover loaded, lowest precedence first.
+func (c Config) Files() []string {
+	return c.files
+}
+
+func mergeAll(base Config, layers []Config) Config {
+	for _, layer := range layers {
+		base = Merge(base, layer)
+	}
+	return base
+}
+
+// loadUserConfig loads the user config file, if any. Without a home or
+// config directory there is simply no user config, unless XDG_CONFIG_HOME
+// names one that cannot be used, such as a relative path.
+func loadUserConfig() (Config, bool, error) {
+	path, found, err := UserConfigFile()
+	if err != nil {
+		if os.Getenv("XDG_CONFIG_HOME") != "" {
+			return Config{}, false, fmt.Errorf("user config: %w", err)
+		}
+		return Config{}, false, nil
+	}
+	if !found {
+		return Config{}, false, nil
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		return Config{}, false, err
+	}
+	return cfg, true, nil
+}
+
+// UserConfigFile returns the user config file Discover loads and whether it
+// exists. When none of UserConfigFiles exists, it returns where a new
+// config.yaml belongs.
+func UserConfigFile() (string, bool, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, err
+	}
+	dir = filepath.Join(dir, "proj-audit")
+	for _, name := range UserConfigFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		return path, true, nil
+	}
+	return filepath.Join(dir, "config.yaml"), false, nil
+}
+
+// loadRootFile reads the config keys of the .proj-audit.yaml in the scan
+// root. That file has two roles and is read twice: here as a config layer for
+// the whole run, and during the scan by LoadProjectFile as the root project's
+// own overrides (category, tags, description, priority, exclude). Each reader
+// ignores the other's keys. A root key is ignored since the root is already
+// chosen.
+func loadRootFile(root string) (Config, bool, error) {
+	for _, name := range ProjectFileNames {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, false, fmt.Errorf("read config: %w", err)
+		}
+		docs, err := yamlMappings(data)
+		if err != nil {
+			return Config{}, false, fmt.Errorf("parse config %s: %w", path, err)
+		}
+		for _, values := range docs {
+			for key := range values {
+				if strings.EqualFold(key, "root") {
+					delete(values, key)
+				}
+			}
+		}
+		cfg, err := fromDocuments(docs, path)
+		if err != nil {
+			return Config{}, false, err
+		}
+		return cfg, true, nil
+	}
+	return Config{}, false, nil
+}
+
+// envLayer builds the layer set by PROJ_AUDIT_* variables and returns
+// PROJ_AUDIT_CONFIG separately. Unknown variables are ignored.
+func envLayer(environ []string) (Config, string, error) {
+	values := make(map[string]interface{})
+	file := ""
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		if name == EnvPrefix+"CONFIG" {
+			file = value
+			continue
+		}
+		field, ok := envFields[name]
+		if !ok {
+			continue
+		}
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch field.Type.Kind() {
+		case reflect.String:
+			values[key] = value
+		case reflect.Int:
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return Config{}, "", fmt.Errorf("%s: expected an integer, got %q", name, value)
+			}
+			values[key] = n
+		case reflect.Bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return Config{}, "", fmt.Errorf("%s: expected true or false, got %q", name, value)
+			}
+			values[key] = b
+		case reflect.Slice:
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			values[key] = items
+		}
+	}
+	cfg, err := FromMap(values, "env")
+	return cfg, file, err
+}
+
+// envFields maps variable names to the scalar and string-list fields of
+// Config they set.
+var envFields = func() map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String, reflect.Int, reflect.Bool:
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.String {
+				continue
+			}
+		default:
+			continue
+		}
+		fields[EnvPrefix+upperSnake(key)] = field
+	}
+	return fields
+}()
+
+// upperSnake turns a camelCase key into UPPER_SNAKE_CASE.
+func upperSnake(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// expandPaths expands a leading ~ in root and the configured file paths.
+func (c *Config) expandPaths() {
+	for _, path := range []*string{&c.Root, &c.LanguagesFile, &c.CIFile, &c.VersionsFile, &c.FrameworksFile, &c.RegistryFile} {
+		*path = ExpandHome(*path)
+	}
+}
+
+// ExpandHome replaces a leading ~ or ~/ with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
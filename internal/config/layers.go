@@ -0,0 +1,276 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultSource names the embedded defaults as an origin.
+const DefaultSource = "defaults"
+
+// origin records that source set the value at path, a dotted JSON key.
+type origin struct {
+	path   string
+	source string
+}
+
+// Setting is one effective config value, JSON-encoded, and the source that
+// set it.
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+}
+
+// Parse decodes a JSON config layer. source names the layer (a file path,
+// "flags", ...) in origins.
+func Parse(data []byte, source string) (Config, error) {
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	cfg.source = source
+	return cfg, nil
+}
+
+// ParseYAML decodes a YAML config layer with the same schema as Parse. Each
+// document of a multi-document stream is merged onto the ones before it as
+// if it were a layer of its own.
+func ParseYAML(data []byte, source string) (Config, error) {
+	docs, err := yamlMappings(data)
+	if err != nil {
+		return Config{}, err
+	}
+	return fromDocuments(docs, source)
+}
+
+// yamlMappings parses a YAML stream whose documents must each be a mapping at
+// the top level.
+func yamlMappings(data []byte) ([]map[string]interface{}, error) {
+	docs, err := parseYAMLStream(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]map[string]interface{}, 0, len(docs))
+	for i, doc := range docs {
+		values, ok := doc.(map[string]interface{})
+		if doc != nil && !ok {
+			if len(docs) > 1 {
+				return nil, fmt.Errorf("document %d: expected a mapping at the top level", i+1)
+			}
+			return nil, fmt.Errorf("expected a mapping at the top level")
+		}
+		out = append(out, values)
+	}
+	return out, nil
+}
+
+// fromDocuments builds one config layer from the documents of a YAML stream.
+func fromDocuments(docs []map[string]interface{}, source string) (Config, error) {
+	if len(docs) == 0 {
+		return FromMap(nil, source)
+	}
+	cfg, err := FromMap(docs[0], source)
+	if err != nil {
+		return Config{}, err
+	}
+	for _, values := range docs[1:] {
+		doc, err := FromMap(values, source)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.documents = append(cfg.documents, doc)
+	}
+	return cfg, nil
+}
+
+// FromMap builds a config layer from values keyed like the JSON config, as
+// Parse would decode them.
+func FromMap(values map[string]interface{}, source string) (Config, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return Config{}, fmt.Errorf("encode %s: %w", source, err)
+	}
+	cfg, err := Parse(data, source)
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", source, err)
+	}
+	return cfg, nil
+}
+
+// has reports whether c sets key. A decoded config knows which keys it
+// contained, so zero and false values count; for a config built in code a
+// non-zero value counts as set.
+func (c Config) has(key string, nonZero bool) bool {
+	if c.present == nil {
+		return nonZero
+	}
+	for _, path := range c.present {
+		if path == key || strings.HasPrefix(path, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeOrigins returns the origins of base followed by everything overrides
+// sets.
+func mergeOrigins(base, overrides Config) []origin {
+	out := append([]origin(nil), base.origins...)
+	out = append(out, overrides.origins...)
+	for _, path := range overrides.present {
+		out = append(out, origin{path: path, source: overrides.source})
+	}
+	return out
+}
+
+// Origin reports which source set the value at key, or DefaultSource. For
+// values that accumulate across layers (ignoreDirs and the language, CI and
+// framework tables) every contributing source is listed.
+func (c Config) Origin(key string) string {
+	return c.origin(key, flatten(DefaultConfig()))
+}
+
+// origin is Origin with the flattened defaults passed in.
+func (c Config) origin(key string, defaults map[string]interface{}) string {
+	if !accumulates(key) {
+		for i := len(c.origins) - 1; i >= 0; i-- {
+			if related(c.origins[i].path, key) {
+				return c.origins[i].source
+			}
+		}
+		return DefaultSource
+	}
+
+	var sources []string
+	if _, ok := defaults[key]; ok {
+		sources = append(sources, DefaultSource)
+	}
+	for _, o := range c.origins {
+		if related(o.path, key) && !contains(sources, o.source) {
+			sources = append(sources, o.source)
+		}
+	}
+	if len(sources) == 0 {
+		return DefaultSource
+	}
+	return strings.Join(sources, " + ")
+}
+
+// Settings lists every effective value by dotted key, in key order.
+func (c Config) Settings() []Setting {
+	values := flatten(c)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	defaults := flatten(DefaultConfig())
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		encoded, _ := json.Marshal(values[key])
+		settings = append(settings, Setting{Key: key, Value: string(encoded), Origin: c.origin(key, defaults)})
+	}
+	return settings
+}
+
+// flatten maps the dotted key of every leaf in c's JSON form to its value.
+// Lists are leaves.
+func flatten(c Config) map[string]interface{} {
+	out := make(map[string]interface{})
+	data, err := json.Marshal(c)
+	if err != nil {
+		return out
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return out
+	}
+	walkLeaves("", generic, func(path string, value interface{}) {
+		out[path] = value
+	})
+	return out
+}
+
+// leafPaths lists the dotted keys of the leaves of a decoded JSON value.
+func leafPaths(prefix string, value interface{}) []string {
+	var paths []string
+	walkLeaves(prefix, value, func(path string, _ interface{}) {
+		paths = append(paths, path)
+	})
+	return paths
+}
+
+func walkLeaves(prefix string, value interface{}, visit func(path string, value interface{})) {
+	fields, ok := value.(map[string]interface{})
+	if !ok || (len(fields) == 0 && prefix != "") {
+		visit(prefix, value)
+		return
+	}
+	for key, child := range fields {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		walkLeaves(path, child, visit)
+	}
+}
+
+// related reports whether one key contains the other.
+func related(a, b string) bool {
+	return a == b || strings.HasPrefix(b, a+".") || strings.HasPrefix(a, b+".")
+}
+
+func accumulates(key string) bool {
+	if key == "ignoreDirs" {
+		return true
+	}
+	for _, prefix := range []string{"languages", "ci", "frameworks"} {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// configKeys maps the lowercased JSON keys of Config to their canonical
+// spelling, since decoding matches keys case-insensitively.
+var configKeys = func() map[string]string {
+	keys := make(map[string]string)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys[strings.ToLower(name)] = name
+		}
+	}
+	return keys
+}()
+
+// presentPaths lists the leaves a raw config object sets, with canonical
+// top-level keys. Unknown keys are left out.
+func presentPaths(raw map[string]interface{}) []string {
+	paths := []string{}
+	for key, value := range raw {
+		canonical, ok := configKeys[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		paths = append(paths, leafPaths(canonical, value)...)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named scoring variant: a partial scoring block deep-merged onto
+// the profile named by Base, or onto the main scoring config when Base is
+// empty or "default".
+type Profile struct {
+	Base    string
+	Scoring map[string]interface{}
+}
+
+// UnmarshalJSON reads a scoring block with an optional "base" key.
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*p = Profile{}
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	if base, ok := fields["base"]; ok {
+		name, ok := base.(string)
+		if !ok {
+			return fmt.Errorf("profile base must be a string, got %v", base)
+		}
+		p.Base = name
+		delete(fields, "base")
+	}
+	p.Scoring = fields
+	return nil
+}
+
+func (p Profile) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(p.Scoring)+1)
+	for key, value := range p.Scoring {
+		fields[key] = value
+	}
+	if p.Base != "" {
+		fields["base"] = p.Base
+	}
+	return json.Marshal(fields)
+}
+
+func defaultProfiles() map[string]Profile {
+	var profiles map[string]Profile
+	if err := decodeYAML(defaultProfilesYAML, &profiles); err != nil {
+		panic(fmt.Sprintf("invalid default profiles yaml: %v", err))
+	}
+	return profiles
+}
+
+// ProfileNames lists the available profiles, including "default".
+func (c Config) ProfileNames() []string {
+	names := []string{"default"}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ScoringProfile returns the scoring config for the named profile: its chain
+// of bases merged onto c.Scoring as described for mergeScoring. "" and
+// "default" return c.Scoring.
+func (c Config) ScoringProfile(name string) (*ScoringConfig, error) {
+	if name == "" || name == "default" {
+		return c.Scoring, nil
+	}
+	generic, err := c.resolveProfile(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := scoringFromGeneric(generic)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return sc, nil
+}
+
+// resolveProfile returns the named profile merged onto its bases, as generic
+// JSON values. seen holds the profiles already visited, to report
+// inheritance cycles.
+func (c Config) resolveProfile(name string, seen []string) (interface{}, error) {
+	if name == "" || name == "default" {
+		return scoringToGeneric(c.Scoring)
+	}
+	for _, visited := range seen {
+		if visited == name {
+			return nil, fmt.Errorf("profile inheritance cycle: %s -> %s", strings.Join(seen, " -> "), name)
+		}
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	base, err := c.resolveProfile(profile.Base, append(seen, name))
+	if err != nil {
+		return nil, err
+	}
+	return mergeScoring(base, profile.Scoring), nil
+}
+
+// ApplyProfile replaces Scoring with the selected profile's and records each
+// profile in its chain as the origin of the scoring keys it sets.
+func (c *Config) ApplyProfile() error {
+	scoring, err := c.ScoringProfile(c.Profile)
+	if err != nil {
+		return err
+	}
+	var chain []string
+	for name := c.Profile; name != "" && name != "default"; name = c.Profiles[name].Base {
+		chain = append([]string{name}, chain...)
+	}
+	for _, name := range chain {
+		for _, path := range leafPaths("scoring", c.Profiles[name].Scoring) {
+			c.origins = append(c.origins, origin{path: path, source: "profile " + name})
+		}
+	}
+	c.Scoring = scoring
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFileNames are the per-project override files, checked in order.
+var ProjectFileNames = []string{".proj-audit.yaml", ".proj-audit.yml"}
+
+// ProjectFile holds the overrides a project declares about itself.
+type ProjectFile struct {
+	// Category pins the project's category regardless of its scores.
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Description string   `json:"description"`
+	// Priority orders projects for attention: higher comes first among
+	// siblings in the tree and markdown output.
+	Priority int  `json:"priority"`
+	Exclude  bool `json:"exclude"`
+}
+
+// LoadProjectFile reads the override file in dir. It returns nil when the
+// project has none.
+func LoadProjectFile(dir string) (*ProjectFile, error) {
+	for _, name := range ProjectFileNames {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read project file: %w", err)
+		}
+		var file ProjectFile
+		if err := decodeYAML(data, &file); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return &file, nil
+	}
+	return nil, nil
+}
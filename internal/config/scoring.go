@@ -1,6 +1,11 @@
 package config
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 func DefaultScoringConfig() *ScoringConfig {
 	var sc ScoringConfig
@@ -9,3 +14,175 @@ func DefaultScoringConfig() *ScoringConfig {
 	}
 	return &sc
 }
+
+// patchScoring deep-merges a scoring block, as decoded from JSON, onto base;
+// see mergeScoring.
+func patchScoring(base *ScoringConfig, patch interface{}) (*ScoringConfig, error) {
+	generic, err := scoringToGeneric(base)
+	if err != nil {
+		return nil, err
+	}
+	return scoringFromGeneric(mergeScoring(generic, patch))
+}
+
+func scoringToGeneric(sc *ScoringConfig) (interface{}, error) {
+	if sc == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return nil, fmt.Errorf("encode scoring: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("encode scoring: %w", err)
+	}
+	return generic, nil
+}
+
+func scoringFromGeneric(generic interface{}) (*ScoringConfig, error) {
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("decode scoring: %w", err)
+	}
+	var sc ScoringConfig
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("decode scoring: %w", err)
+	}
+	return &sc, nil
+}
+
+// mergeScoring is deepMerge with one addition: a categories patch in the
+// legacy object form is merged into the base rules by name instead of
+// replacing them.
+func mergeScoring(base, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepMerge(base, patch)
+	}
+	if legacy, ok := patchMap["categories"].(map[string]interface{}); ok {
+		baseMap, _ := base.(map[string]interface{})
+		baseRules, _ := baseMap["categories"].([]interface{})
+		copied := make(map[string]interface{}, len(patchMap))
+		for key, value := range patchMap {
+			copied[key] = value
+		}
+		copied["categories"] = mergeCategoriesByName(baseRules, legacy)
+		patchMap = copied
+	}
+	return deepMerge(base, patchMap)
+}
+
+// mergeCategoriesByName applies legacy category entries to base: an entry
+// naming an existing rule is deep-merged into it (null removes the rule), and
+// a new one is inserted before the trailing catch-all rule.
+func mergeCategoriesByName(base []interface{}, legacy map[string]interface{}) []interface{} {
+	rules := append([]interface{}(nil), base...)
+	for _, key := range legacyKeyOrder(legacy) {
+		patch := legacy[key]
+		name := key
+		if known, ok := legacyCategoryName(key); ok {
+			name = known
+		} else if rule, ok := patch.(map[string]interface{}); ok {
+			if named, ok := rule["name"].(string); ok && named != "" {
+				name = named
+			}
+		}
+
+		index := -1
+		for i, rule := range rules {
+			if ruleName(rule) != "" && strings.EqualFold(ruleName(rule), name) {
+				index = i
+				break
+			}
+		}
+		switch {
+		case patch == nil && index >= 0:
+			rules = append(rules[:index], rules[index+1:]...)
+		case patch == nil:
+		case index >= 0:
+			rules[index] = deepMerge(rules[index], patch)
+		default:
+			rule, ok := deepMerge(nil, patch).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rule["name"] = name
+			at := len(rules)
+			if at > 0 && isCatchAll(rules[at-1]) {
+				at--
+			}
+			rules = append(rules[:at], append([]interface{}{rule}, rules[at:]...)...)
+		}
+	}
+	return rules
+}
+
+// legacyKeyOrder lists the keys of a legacy categories object in the order
+// CategoryConfig.UnmarshalJSON uses: the known keys first, then the rest
+// alphabetically.
+func legacyKeyOrder(legacy map[string]interface{}) []string {
+	var known, extra []string
+	for _, entry := range legacyCategories {
+		if _, ok := legacy[entry.key]; ok {
+			known = append(known, entry.key)
+		}
+	}
+	for key := range legacy {
+		if _, ok := legacyCategoryName(key); !ok {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(known, extra...)
+}
+
+func legacyCategoryName(key string) (string, bool) {
+	for _, entry := range legacyCategories {
+		if entry.key == key {
+			return entry.name, true
+		}
+	}
+	return "", false
+}
+
+func ruleName(rule interface{}) string {
+	fields, _ := rule.(map[string]interface{})
+	name, _ := fields["name"].(string)
+	return name
+}
+
+// isCatchAll reports whether a rule has no bounds or expression, so it
+// matches every project.
+func isCatchAll(rule interface{}) bool {
+	fields, _ := rule.(map[string]interface{})
+	for key := range fields {
+		if key != "name" && key != "color" {
+			return false
+		}
+	}
+	return true
+}
+
+// deepMerge overlays patch onto base: maps merge key by key, a null value
+// removes the key, and any other value (lists included) replaces the one in
+// base. Neither argument is modified.
+func deepMerge(base, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	baseMap, _ := base.(map[string]interface{})
+	out := make(map[string]interface{}, len(baseMap)+len(patchMap))
+	for key, value := range baseMap {
+		out[key] = value
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(out, key)
+			continue
+		}
+		out[key] = deepMerge(baseMap[key], value)
+	}
+	return out
+}
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StarterOptions tunes the config Starter writes to what was found in a scan
+// root.
+type StarterOptions struct {
+	// Root is the directory the options were detected in, or "" when nothing
+	// was detected.
+	Root string
+	// Languages limits the languages block to these names; nil lists every
+	// built-in language.
+	Languages []string
+	// IgnoreDirs are directories found under Root to skip besides the
+	// defaults.
+	IgnoreDirs []string
+}
+
+// analyzerNotes describes each analyzer next to its toggle.
+var analyzerNotes = map[string]string{
+	"description": "one-line summary from the manifest or README",
+	"framework":   "frameworks and project type",
+	"fs":          "files, sizes, README, license, CI, Docker and tests",
+	"git":         "commits, active days and last change",
+	"lang":        "languages, lines of code and test code",
+	"manifest":    "dependency counts",
+	"staleness":   "outdated toolchains and packages, against versionsFile",
+}
+
+// Starter renders the built-in defaults as a commented YAML config, as
+// written by proj-audit init.
+func Starter(opts StarterOptions) []byte {
+	defaults := DefaultConfig()
+	w := &starterWriter{}
+
+	w.comment(0, "proj-audit config, written by `proj-audit init` from the built-in defaults. "+
+		"Every value shown is a default: delete the ones you don't change so they keep up with new releases. "+
+		"Lists replace the defaults, except ignoreDirs and the languages, ci and frameworks tables, which add to them. "+
+		"Check your edits with `proj-audit config validate`.")
+	if opts.Root != "" {
+		w.comment(0, fmt.Sprintf("Languages and ignoreDirs are tuned to what was found under %s.", opts.Root))
+	}
+
+	w.blank()
+	w.comment(0, "Directory to scan; a leading ~ is expanded. Defaults to the current directory.")
+	w.commented(0, "root: ~/dev")
+	w.blank()
+	w.comment(0, "How many directory levels to descend below root; 0 means no limit.")
+	w.field(0, "maxDepth", defaults.MaxDepth)
+	w.blank()
+	w.comment(0, "Report format: tree, markdown or json.")
+	w.field(0, "format", defaults.Format)
+	w.blank()
+	w.comment(0, "Also scan directories whose names start with a dot.")
+	w.field(0, "includeHidden", defaults.IncludeHidden)
+	w.blank()
+	w.comment(0, "Directory names that are never scanned, on top of the built-in ones listed here and each language's skipDirs.")
+	w.line(0, "ignoreDirs:")
+	for _, dir := range defaults.IgnoreDirs {
+		w.line(2, "- "+yamlString(dir))
+	}
+	for _, dir := range opts.IgnoreDirs {
+		if indexOf(defaults.IgnoreDirs, dir) < 0 {
+			w.line(2, "- "+yamlString(dir)+"  # found under root")
+		}
+	}
+
+	w.blank()
+	w.comment(0, "Scoring profile to use: default, one of the presets (showcase, cleanup, risk) or one defined under profiles.")
+	w.commented(0, "profile: showcase")
+
+	w.blank()
+	w.comment(0, "Extra tables merged onto the built-in ones: languages (in the form of the languages block below), "+
+		"CI markers, framework rules, and the current versions used to flag stale projects.")
+	w.commented(0, "languagesFile: ~/.config/proj-audit/languages.yaml")
+	w.commented(0, "ciFile: ~/.config/proj-audit/ci.yaml")
+	w.commented(0, "frameworksFile: ~/.config/proj-audit/frameworks.yaml")
+	w.commented(0, "versionsFile: ~/.config/proj-audit/versions.yaml")
+	w.comment(0, "Tags and notes registry; defaults to proj-audit/registry.json in the user config directory.")
+	w.commented(0, "registryFile: ~/.config/proj-audit/registry.json")
+
+	w.blank()
+	w.comment(0, "Languages by name. extensions identify a language's files and skipDirs are never scanned; "+
+		"testFiles and testDirs (globs) and testMarkers (source lines after which the rest of a file is test code) pick out tests. "+
+		"Entries add to the built-in table, and a built-in language's lists add to its own.")
+	names := opts.Languages
+	if names == nil {
+		for name := range defaults.Languages {
+			names = append(names, name)
+		}
+	} else {
+		var others []string
+		for name := range defaults.Languages {
+			if indexOf(names, name) < 0 {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(others)
+		if len(others) > 0 {
+			w.comment(0, fmt.Sprintf("Only the languages found under root are listed; the other built-in ones (%s) still apply.", strings.Join(others, ", ")))
+		}
+	}
+	sort.Strings(names)
+	w.line(0, "languages:")
+	for _, name := range names {
+		lang, ok := defaults.Languages[name]
+		if !ok {
+			continue
+		}
+		w.field(2, yamlString(name), lang)
+	}
+	w.comment(0, "The ci and frameworks tables work the same way; `proj-audit config show` prints the built-in ones.")
+
+	w.blank()
+	w.comment(0, "Analyzers to run; false turns one off, like --disable-analyzers.")
+	w.line(0, "analyzers:")
+	var analyzers []string
+	for name := range defaults.Analyzers {
+		analyzers = append(analyzers, name)
+	}
+	sort.Strings(analyzers)
+	for _, name := range analyzers {
+		entry := fmt.Sprintf("%s: %t", name, defaults.Analyzers[name])
+		if note := analyzerNotes[name]; note != "" {
+			entry += "  # " + note
+		}
+		w.line(2, entry)
+	}
+
+	sc := defaults.Scoring
+	w.blank()
+	w.comment(0, "Scoring. Each project earns effort, polish and recency points and takes the first category rule it matches; "+
+		"the README covers curves, expressions and profiles in full.")
+	w.line(0, "scoring:")
+	w.line(2, "effort:")
+	w.comment(4, "Points by total commits. The first threshold reached applies, so list them from the highest min down.")
+	w.field(4, "commit", sc.Effort.Commit)
+	w.comment(4, "Points by the number of days with commits, listed the same way.")
+	w.field(4, "active", sc.Effort.Active)
+	w.comment(4, "A logarithmic curve can replace the commit thresholds:")
+	w.commented(4, "commitCurve: {type: logarithmic, points: 15, saturation: 100}")
+	w.line(2, "polish:")
+	w.comment(4, "Points for having each of these.")
+	w.field(4, "readme", sc.Polish.Readme)
+	w.field(4, "tests", sc.Polish.Tests)
+	w.field(4, "ci", sc.Polish.CI)
+	w.field(4, "docker", sc.Polish.Docker)
+	w.field(4, "license", sc.Polish.License)
+	w.comment(4, "Points by the share of lines that are test code, highest min first.")
+	w.field(4, "testRatio", sc.Polish.TestRatio)
+	w.line(2, "recency:")
+	w.comment(4, "Points by days since the project was last touched; the first threshold whose maxDays covers it applies.")
+	w.field(4, "thresholds", sc.Recency.Thresholds)
+	w.comment(4, "A curve can replace the thresholds, e.g. one halving every 180 days:")
+	w.commented(4, "curve: {type: exponential, points: 5, halfLife: 180}")
+	w.comment(2, "Extra points for the effort, polish or recency score when an expression holds, e.g.:")
+	w.commented(2, "custom:")
+	w.commented(2, "  - name: go-veteran")
+	w.commented(2, "    score: effort")
+	w.commented(2, `    when: '"Go" in languages && commits > 50'`)
+	w.commented(2, "    points: 4")
+	w.comment(2, "Category rules. A project takes the first rule whose bounds (commitMax, effortMin, effortMax, polishMin, "+
+		"polishMax, recencyMin, recencyMax) and when expression all hold, so a rule without any is the catch-all. "+
+		"color is red, green, yellow, blue, magenta, cyan, white or gray.")
+	w.field(2, "categories", sc.Categories)
+
+	w.blank()
+	w.comment(0, "Named scoring profiles, picked with --profile or profile. Each is a partial scoring block merged onto its base: "+
+		"default (the scoring block above) or another profile, such as the built-in showcase, cleanup and risk.")
+	w.commented(0, "profiles:")
+	w.commented(0, "  mine:")
+	w.commented(0, "    base: showcase")
+	w.commented(0, "    polish:")
+	w.commented(0, "      docker: 0")
+
+	return []byte(w.b.String())
+}
+
+type starterWriter struct {
+	b strings.Builder
+}
+
+func (w *starterWriter) line(indent int, text string) {
+	w.b.WriteString(strings.Repeat(" ", indent))
+	w.b.WriteString(text)
+	w.b.WriteByte('\n')
+}
+
+func (w *starterWriter) blank() {
+	w.b.WriteByte('\n')
+}
+
+// commented writes a line of YAML that is commented out.
+func (w *starterWriter) commented(indent int, text string) {
+	w.line(indent, "# "+text)
+}
+
+// comment writes text as comment lines wrapped to 80 columns.
+func (w *starterWriter) comment(indent int, text string) {
+	line := "#"
+	for _, word := range strings.Fields(text) {
+		if len(line) > 1 && indent+len(line)+1+len(word) > 80 {
+			w.line(indent, line)
+			line = "#"
+		}
+		line += " " + word
+	}
+	w.line(indent, line)
+}
+
+// field writes key and value, laid out like the embedded defaults.
+func (w *starterWriter) field(indent int, key string, value interface{}) {
+	w.node(indent, key+":", reflect.ValueOf(value))
+}
+
+// node writes prefix followed by v: scalars on the same line, and the fields
+// of a struct or map and the items of a slice on the lines below, two spaces
+// further in.
+func (w *starterWriter) node(indent int, prefix string, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		entries := yamlEntries(v)
+		if len(entries) == 0 {
+			w.line(indent, prefix+" {}")
+			return
+		}
+		w.line(indent, prefix)
+		for _, entry := range entries {
+			w.node(indent+2, entry.key+":", entry.value)
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			w.line(indent, prefix+" []")
+			return
+		}
+		w.line(indent, prefix)
+		for i := 0; i < v.Len(); i++ {
+			w.item(indent+2, v.Index(i))
+		}
+	default:
+		w.line(indent, prefix+" "+yamlScalar(v))
+	}
+}
+
+// item writes a sequence item, putting the first field of a struct or map on
+// the dash line.
+func (w *starterWriter) item(indent int, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		w.node(indent, "-", v)
+		return
+	}
+	entries := yamlEntries(v)
+	if len(entries) == 0 {
+		w.line(indent, "- {}")
+		return
+	}
+	for i, entry := range entries {
+		prefix := entry.key + ":"
+		if i == 0 {
+			prefix = "- " + prefix
+		} else {
+			prefix = "  " + prefix
+		}
+		w.node(indent, prefix, entry.value)
+	}
+}
+
+type yamlEntry struct {
+	key   string
+	value reflect.Value
+}
+
+// yamlEntries lists the fields of a struct by their json keys, in order and
+// skipping empty omitempty ones, or the entries of a map sorted by key.
+func yamlEntries(v reflect.Value) []yamlEntry {
+	var entries []yamlEntry
+	if v.Kind() == reflect.Map {
+		for _, key := range v.MapKeys() {
+			entries = append(entries, yamlEntry{yamlString(fmt.Sprint(key.Interface())), v.MapIndex(key)})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		return entries
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if !field.IsExported() || tag[0] == "-" {
+			continue
+		}
+		value := v.Field(i)
+		if value.IsZero() && (indexOf(tag[1:], "omitempty") >= 0 || value.Kind() == reflect.Slice) {
+			continue
+		}
+		key := tag[0]
+		if key == "" {
+			key = field.Name
+		}
+		entries = append(entries, yamlEntry{key, value})
+	}
+	return entries
+}
+
+func yamlScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return yamlString(v.String())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z0-9_./]([A-Za-z0-9_./ +-]*[A-Za-z0-9_./+-])?$`)
+
+// yamlString writes s plain when it reads back as the same string, and
+// double-quoted otherwise.
+func yamlString(s string) string {
+	if plainYAML.MatchString(s) && parseScalar(s) == s {
+		return s
+	}
+	return strconv.Quote(s)
+}
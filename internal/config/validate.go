@@ -0,0 +1,556 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue Validate found. Line and Column are zero when the value
+// did not come from a file, in which case Source names its layer.
+type Problem struct {
+	Source  string
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	location := p.Source
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", p.Source, p.Line, p.Column)
+	}
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s", location, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, p.Key, p.Message)
+}
+
+// KeyError is an error about the value at Key, a path within the block being
+// checked such as categories[2].when.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string { return e.Err.Error() }
+
+func (e *KeyError) Unwrap() error { return e.Err }
+
+// Validate checks the files Discover loaded, and the languages, CI,
+// frameworks and versions files, for keys the schema does not know, then
+// checks the merged config: thresholds must be in the order the scorer reads
+// them, every category rule must be able to match, and analyzer names must
+// exist. Each scoring block, the main one and every profile's, is also passed
+// to checks, whose KeyErrors are located like the rest.
+func (c Config) Validate(checks ...func(*ScoringConfig) error) []Problem {
+	v := &validator{cfg: c, positions: make(map[string]map[string]position)}
+
+	for _, file := range c.files {
+		schema := reflect.TypeOf(Config{})
+		var extra []string
+		for _, name := range ProjectFileNames {
+			if filepath.Base(file) == name {
+				// The scan root's file also holds the root project's overrides.
+				extra = jsonKeys(reflect.TypeOf(ProjectFile{}))
+			}
+		}
+		v.checkFile(file, schema, extra)
+	}
+	tables := []struct {
+		path   string
+		schema interface{}
+	}{
+		{c.LanguagesFile, map[string]LanguageConfig{}},
+		{c.CIFile, map[string]CIConfig{}},
+		{c.FrameworksFile, map[string]FrameworkConfig{}},
+		{c.VersionsFile, VersionTable{}},
+	}
+	for _, table := range tables {
+		if table.path != "" {
+			v.checkFile(table.path, reflect.TypeOf(table.schema), nil)
+		}
+	}
+
+	if _, err := c.ScoringProfile(c.Profile); err != nil {
+		v.add("profile", err.Error())
+	}
+	v.checkScoring("", c.Scoring, checks)
+	for _, name := range c.ProfileNames()[1:] {
+		scoring, err := c.ScoringProfile(name)
+		if err != nil {
+			v.add("profiles."+name+".base", err.Error())
+			continue
+		}
+		v.checkScoring(name, scoring, checks)
+	}
+	v.checkAnalyzers()
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		a, b := v.problems[i], v.problems[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return v.problems
+}
+
+type validator struct {
+	cfg Config
+	// positions holds the positions of the keys in each file read, by path.
+	positions map[string]map[string]position
+	problems  []Problem
+	seen      map[Problem]bool
+}
+
+func (v *validator) report(p Problem) {
+	if v.seen == nil {
+		v.seen = make(map[Problem]bool)
+	}
+	if !v.seen[p] {
+		v.seen[p] = true
+		v.problems = append(v.problems, p)
+	}
+}
+
+// add reports a problem with the value at key, in the file that set it.
+func (v *validator) add(key, message string) {
+	source := v.cfg.Origin(indexPattern.ReplaceAllString(key, ""))
+	if i := strings.LastIndex(source, " + "); i >= 0 {
+		source = source[i+3:]
+	}
+	problem := Problem{Source: source, Key: key, Message: message}
+	if positions, ok := v.positions[source]; ok {
+		// Fall back to the nearest enclosing key the file has.
+		for path := key; path != ""; path = parentPath(path) {
+			if pos, ok := positions[path]; ok {
+				problem.Line, problem.Column = pos.line, pos.col
+				break
+			}
+		}
+	}
+	v.report(problem)
+}
+
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// checkFile reports the keys in a file that schema does not define, besides
+// those in extra.
+func (v *validator) checkFile(path string, schema reflect.Type, extra []string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.report(Problem{Source: path, Message: err.Error()})
+		return
+	}
+	root, positions, err := parseWithPositions(path, data)
+	if err != nil {
+		problem := Problem{Source: path, Message: err.Error()}
+		var yerr *yamlError
+		var syntax *json.SyntaxError
+		switch {
+		case errors.As(err, &yerr):
+			problem.Line, problem.Column, problem.Message = yerr.line, yerr.col, yerr.msg
+		case errors.As(err, &syntax):
+			pos := offsetPosition(data, int(syntax.Offset))
+			problem.Line, problem.Column = pos.line, pos.col
+		}
+		v.report(problem)
+		return
+	}
+	v.positions[path] = positions
+
+	if fields, ok := root.(map[string]interface{}); ok {
+		for _, key := range extra {
+			delete(fields, key)
+		}
+	}
+	checkKeys(root, schema, nil, func(keyPath []string, message string) {
+		key := joinPath(keyPath)
+		pos := positions[key]
+		v.report(Problem{Source: path, Line: pos.line, Column: pos.col, Key: key, Message: message})
+	})
+}
+
+// checkKeys walks value along the Go type it decodes into and reports every
+// map key that type does not have a field for.
+func checkKeys(value interface{}, t reflect.Type, path []string, report func(path []string, message string)) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	child := func(elem string) []string {
+		return append(path[:len(path):len(path)], elem)
+	}
+	switch t {
+	case reflect.TypeOf(Profile{}):
+		if fields, ok := value.(map[string]interface{}); ok {
+			scoring := make(map[string]interface{}, len(fields))
+			for key, field := range fields {
+				if key != "base" {
+					scoring[key] = field
+				}
+			}
+			checkKeys(scoring, reflect.TypeOf(ScoringConfig{}), path, report)
+		}
+		return
+	case reflect.TypeOf(CategoryConfig{}):
+		if legacy, ok := value.(map[string]interface{}); ok {
+			for key, rule := range legacy {
+				if rule != nil {
+					checkKeys(rule, reflect.TypeOf(CategoryRule{}), child(key), report)
+				}
+			}
+			return
+		}
+	case reflect.TypeOf(RecencyConfig{}):
+		if _, ok := value.([]interface{}); ok {
+			checkKeys(value, reflect.TypeOf([]AgeThreshold{}), path, report)
+			return
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields, _ := value.(map[string]interface{})
+		known := jsonKeys(t)
+		for key, field := range fields {
+			if indexOf(known, key) < 0 {
+				message := fmt.Sprintf("unknown key %q", key)
+				if suggestion := suggest(key, known); suggestion != "" {
+					message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				report(child(key), message)
+				continue
+			}
+			checkKeys(field, fieldByKey(t, key).Type, child(key), report)
+		}
+	case reflect.Map:
+		fields, _ := value.(map[string]interface{})
+		for key, field := range fields {
+			checkKeys(field, t.Elem(), child(key), report)
+		}
+	case reflect.Slice:
+		items, _ := value.([]interface{})
+		for i, item := range items {
+			checkKeys(item, t.Elem(), child(fmt.Sprintf("[%d]", i)), report)
+		}
+	}
+}
+
+func jsonKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func fieldByKey(t reflect.Type, key string) reflect.StructField {
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == key {
+			return t.Field(i)
+		}
+	}
+	return reflect.StructField{}
+}
+
+func indexOf(items []string, value string) int {
+	for i, item := range items {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// suggest returns the known key that key is most likely a typo of: one that
+// differs only in case, or by one edit (two for longer keys).
+func suggest(key string, known []string) string {
+	limit := 1
+	if len(key) > 4 {
+		limit = 2
+	}
+	best, bestDistance := "", limit+1
+	for _, candidate := range known {
+		if strings.EqualFold(candidate, key) {
+			return candidate
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent characters that turn a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// checkScoring checks one resolved scoring block: the main one when profile
+// is empty, otherwise the named profile's.
+func (v *validator) checkScoring(profile string, sc *ScoringConfig, checks []func(*ScoringConfig) error) {
+	if sc == nil {
+		return
+	}
+	add := func(key, format string, args ...interface{}) {
+		v.add(v.cfg.scoringKey(profile, key), fmt.Sprintf(format, args...))
+	}
+
+	descending := func(key string, mins []float64) {
+		for i := 1; i < len(mins); i++ {
+			if mins[i] >= mins[i-1] {
+				add(fmt.Sprintf("%s[%d]", key, i), "min %g is not below the previous threshold's min %g; list thresholds from the highest min down, since the first one reached applies", mins[i], mins[i-1])
+			}
+		}
+	}
+	var mins []float64
+	for _, th := range sc.Effort.Commit {
+		mins = append(mins, float64(th.Min))
+	}
+	descending("effort.commit", mins)
+	mins = nil
+	for _, th := range sc.Effort.Active {
+		mins = append(mins, float64(th.Min))
+	}
+	descending("effort.active", mins)
+	mins = nil
+	for _, th := range sc.Polish.TestRatio {
+		mins = append(mins, th.Min)
+	}
+	descending("polish.testRatio", mins)
+	for i := 1; i < len(sc.Recency.Thresholds); i++ {
+		cur, prev := sc.Recency.Thresholds[i].MaxDays, sc.Recency.Thresholds[i-1].MaxDays
+		if cur <= prev {
+			add(fmt.Sprintf("recency.thresholds[%d]", i), "maxDays %d is not above the previous threshold's maxDays %d; list thresholds from the fewest days up, since the first one reached applies", cur, prev)
+		}
+	}
+
+	for i, rule := range sc.Categories {
+		key := fmt.Sprintf("categories[%d]", i)
+		if bound := emptyBound(rule); bound != "" {
+			add(key, "category %q never matches: %s", rule.Name, bound)
+			continue
+		}
+		for _, earlier := range sc.Categories[:i] {
+			if earlier.When == "" && covers(earlier, rule) {
+				add(key, "category %q never matches: it overlaps %q, listed earlier, which accepts every project it would", rule.Name, earlier.Name)
+				break
+			}
+		}
+	}
+
+	for _, check := range checks {
+		for _, err := range unjoin(check(sc)) {
+			key := ""
+			var keyErr *KeyError
+			if errors.As(err, &keyErr) {
+				key = keyErr.Key
+			}
+			v.add(strings.TrimSuffix(v.cfg.scoringKey(profile, key), "."), err.Error())
+		}
+	}
+}
+
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// bound is one of a category rule's ranges; nil limits are open.
+type bound struct {
+	low, high       *int
+	lowKey, highKey string
+}
+
+func bounds(rule CategoryRule) []bound {
+	return []bound{
+		{nil, rule.CommitMax, "", "commitMax"},
+		{rule.EffortMin, rule.EffortMax, "effortMin", "effortMax"},
+		{rule.PolishMin, rule.PolishMax, "polishMin", "polishMax"},
+		{rule.RecencyMin, rule.RecencyMax, "recencyMin", "recencyMax"},
+	}
+}
+
+// emptyBound describes a range of rule that no value can fall in.
+func emptyBound(rule CategoryRule) string {
+	for _, b := range bounds(rule) {
+		if b.low != nil && b.high != nil && *b.low > *b.high {
+			return fmt.Sprintf("%s %d is above %s %d", b.lowKey, *b.low, b.highKey, *b.high)
+		}
+	}
+	return ""
+}
+
+// covers reports whether every range of outer contains the same range of
+// inner, so outer matches whatever inner does.
+func covers(outer, inner CategoryRule) bool {
+	outerBounds, innerBounds := bounds(outer), bounds(inner)
+	for i := range outerBounds {
+		o, in := outerBounds[i], innerBounds[i]
+		if o.low != nil && (in.low == nil || *in.low < *o.low) {
+			return false
+		}
+		if o.high != nil && (in.high == nil || *in.high > *o.high) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkAnalyzers reports analyzer toggles that name no analyzer.
+func (v *validator) checkAnalyzers() {
+	var known []string
+	for name := range defaultAnalyzerToggles() {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	for name := range v.cfg.Analyzers {
+		if indexOf(known, strings.ToLower(name)) >= 0 {
+			continue
+		}
+		message := fmt.Sprintf("unknown analyzer %q", name)
+		if suggestion := suggest(name, known); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		} else {
+			message += fmt.Sprintf(" (known: %s)", strings.Join(known, ", "))
+		}
+		v.add("analyzers."+name, message)
+	}
+}
+
+// scoringKey returns the config key that set key, a path within the scoring
+// block, for the named profile: the first profile in its chain of bases that
+// sets it, or the main scoring block.
+func (c Config) scoringKey(profile, key string) string {
+	plain := indexPattern.ReplaceAllString(key, "")
+	for name, depth := profile, 0; name != "" && name != "default" && depth <= len(c.Profiles); name, depth = c.Profiles[name].Base, depth+1 {
+		for _, path := range leafPaths("", c.Profiles[name].Scoring) {
+			if plain == "" || related(path, plain) {
+				return "profiles." + name + "." + key
+			}
+		}
+	}
+	return "scoring." + key
+}
+
+// parseWithPositions decodes a JSON or YAML file to generic values along
+// with the position of every key and list entry.
+func parseWithPositions(path string, data []byte) (interface{}, map[string]position, error) {
+	if isYAML(path, data) {
+		return parseYAMLPositions(data)
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+	positions, err := jsonPositions(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return root, positions, nil
+}
+
+// jsonPositions records where each key and array element of a JSON document
+// starts, by path.
+func jsonPositions(data []byte) (map[string]position, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	positions := make(map[string]position)
+	// start finds the next token after the decoder's offset.
+	start := func() position {
+		off := int(dec.InputOffset())
+		for off < len(data) && strings.IndexByte(" \t\r\n,:", data[off]) >= 0 {
+			off++
+		}
+		return offsetPosition(data, off)
+	}
+
+	var walk func(path []string) error
+	walk = func(path []string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return nil
+		}
+		for i := 0; dec.More(); i++ {
+			pos := start()
+			elem := fmt.Sprintf("[%d]", i)
+			if delim == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				elem = fmt.Sprint(key)
+			}
+			child := append(path[:len(path):len(path)], elem)
+			positions[joinPath(child)] = pos
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+	return positions, walk(nil)
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column.
+func offsetPosition(data []byte, offset int) position {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(before, '\n')
+	return position{line: line, col: col}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// VersionTable is the locally maintained list of current toolchain and
+// package versions used to flag stale projects without network access.
+type VersionTable struct {
+	Toolchains map[string]Version            `json:"toolchains"`
+	Packages   map[string]map[string]Version `json:"packages"`
+	MinorLag   int                           `json:"minorLag"`
+}
+
+// Version accepts both strings and bare numbers, since unquoted YAML
+// versions such as 18 or 1.22 decode as numbers. A number keeps its source
+// text, so 1.20 stays 1.20.
+type Version string
+
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = Version(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("version must be a string or number: %s", data)
+	}
+	*v = Version(n.String())
+	return nil
+}
+
+func LoadVersionsFile(path string) (VersionTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return VersionTable{}, fmt.Errorf("read versions file: %w", err)
+	}
+	var table VersionTable
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		err = json.Unmarshal(data, &table)
+	} else {
+		err = decodeYAML(data, &table)
+	}
+	if err != nil {
+		return VersionTable{}, fmt.Errorf("parse versions file: %w", err)
+	}
+	return table, nil
+}
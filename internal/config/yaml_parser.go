@@ -3,19 +3,52 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-type yamlToken struct {
-	indent int
-	text   string
-	line   int
+// The YAML parser covers what config files written by hand use: block maps
+// and sequences at any consistent indentation, flow collections, plain,
+// quoted and block scalars, comments, anchors, aliases and "<<" merge keys,
+// and multi-document streams. Tags and complex keys are rejected. Errors
+// carry the line and column they were found at.
+
+type yamlError struct {
+	line, col int
+	msg       string
+}
+
+func (e *yamlError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.line, e.col, e.msg)
 }
 
 type yamlParser struct {
-	tokens []yamlToken
-	index  int
+	// lines are rewritten in place as "- " and anchor prefixes are consumed,
+	// with spaces so columns stay intact.
+	lines   []string
+	line    int
+	anchors map[string]interface{}
+
+	// anchorSizes holds the node count of each anchored value, sizes that of
+	// every collection counted so far, and expanded the nodes aliases have
+	// added to the stream; see maxAliasNodes.
+	anchorSizes map[string]int
+	sizes       map[collectionKey]int
+	expanded    int
+
+	// positions, when set, records where each key and sequence entry starts,
+	// by the path to it (see joinPath).
+	positions map[string]position
+	path      []string
+}
+
+// position is a 1-based line and column.
+type position struct {
+	line, col int
 }
 
 func decodeYAML(data []byte, out interface{}) error {
@@ -30,180 +63,1121 @@ func decodeYAML(data []byte, out interface{}) error {
 	return json.Unmarshal(buf, out)
 }
 
+// parseYAML parses a YAML stream. When it holds several documents, later
+// ones are merged onto earlier ones key by key.
 func parseYAML(data []byte) (interface{}, error) {
-	tokens, err := tokenizeYAML(string(data))
+	docs, err := parseYAMLStream(data)
 	if err != nil {
 		return nil, err
 	}
-	if len(tokens) == 0 {
-		return nil, nil
+	return mergeStream(docs), nil
+}
+
+// parseYAMLPositions is parseYAML that also reports where each key and
+// sequence entry starts. A key set by several documents is reported where
+// the last one sets it.
+func parseYAMLPositions(data []byte) (interface{}, map[string]position, error) {
+	positions := make(map[string]position)
+	docs, err := newYAMLParser(data, positions).parseStream()
+	if err != nil {
+		return nil, nil, err
+	}
+	return mergeStream(docs), positions, nil
+}
+
+// parseYAMLStream parses every document in a YAML stream.
+func parseYAMLStream(data []byte) ([]interface{}, error) {
+	return newYAMLParser(data, nil).parseStream()
+}
+
+func newYAMLParser(data []byte, positions map[string]position) *yamlParser {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+	return &yamlParser{lines: lines, positions: positions}
+}
+
+func (p *yamlParser) parseStream() ([]interface{}, error) {
+	var docs []interface{}
+	for {
+		doc, found, err := p.parseDocument()
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return docs, nil
+		}
+		docs = append(docs, doc)
+	}
+}
+
+func mergeStream(docs []interface{}) interface{} {
+	var root interface{}
+	for _, doc := range docs {
+		if doc != nil {
+			root = mergeDocuments(root, doc)
+		}
+	}
+	return root
+}
+
+// mergeDocuments overlays patch onto base: maps merge key by key and any
+// other value, null included, replaces the one in base.
+func mergeDocuments(base, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	baseMap, baseOK := base.(map[string]interface{})
+	if !ok || !baseOK {
+		return patch
+	}
+	out := make(map[string]interface{}, len(baseMap)+len(patchMap))
+	for key, value := range baseMap {
+		out[key] = value
+	}
+	for key, value := range patchMap {
+		out[key] = mergeDocuments(baseMap[key], value)
+	}
+	return out
+}
+
+// maxAliasNodes caps how many nodes aliases may add to a stream. An alias
+// shares the anchored value, but decoding copies it on every use, so a few
+// nested anchors could otherwise expand to billions of nodes.
+const maxAliasNodes = 100000
+
+// collectionKey identifies a map or slice by its backing storage.
+type collectionKey struct {
+	ptr uintptr
+	len int
+}
+
+// anchor records value under name along with its size.
+func (p *yamlParser) anchor(name string, value interface{}) {
+	p.anchors[name] = value
+	p.anchorSizes[name] = p.nodeSize(value)
+}
+
+// alias returns the value anchored as name, counting its nodes against
+// maxAliasNodes. line and col locate the alias, 0-based.
+func (p *yamlParser) alias(name string, line, col int) (interface{}, error) {
+	value, ok := p.anchors[name]
+	if !ok {
+		return nil, p.errorf(line, col, "unknown alias %q", "*"+name)
+	}
+	p.expanded += p.anchorSizes[name]
+	if p.expanded > maxAliasNodes {
+		return nil, p.errorf(line, col, "aliases expand to more than %d nodes", maxAliasNodes)
+	}
+	return value, nil
+}
+
+// nodeSize counts the nodes in value as decoding will copy them. Collections
+// already counted are looked up rather than walked again, so values built
+// from aliases are sized in time linear to what the file spells out.
+func (p *yamlParser) nodeSize(value interface{}) int {
+	var key collectionKey
+	switch v := value.(type) {
+	case map[string]interface{}:
+		key = collectionKey{reflect.ValueOf(v).Pointer(), len(v)}
+	case []interface{}:
+		key = collectionKey{reflect.ValueOf(v).Pointer(), len(v)}
+	}
+	if key.len > 0 {
+		if n, ok := p.sizes[key]; ok {
+			return n
+		}
+	}
+	n := 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			n += p.nodeSize(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			n += p.nodeSize(child)
+		}
+	}
+	if key.len > 0 {
+		p.sizes[key] = n
+	}
+	return n
+}
+
+// enter appends elem to the current path, recording where it starts, and
+// returns a function that removes it again.
+func (p *yamlParser) enter(elem string, line, col int) func() {
+	if p.positions == nil {
+		return func() {}
+	}
+	p.path = append(p.path, elem)
+	p.positions[joinPath(p.path)] = position{line: line + 1, col: col + 1}
+	return func() { p.path = p.path[:len(p.path)-1] }
+}
+
+// joinPath renders a path as dotted keys with [i] for sequence entries, as in
+// scoring.categories[2].name.
+func joinPath(path []string) string {
+	var b strings.Builder
+	for _, elem := range path {
+		if b.Len() > 0 && !strings.HasPrefix(elem, "[") {
+			b.WriteByte('.')
+		}
+		b.WriteString(elem)
+	}
+	return b.String()
+}
+
+func (p *yamlParser) errorf(line, col int, format string, args ...interface{}) error {
+	return &yamlError{line: line + 1, col: col + 1, msg: fmt.Sprintf(format, args...)}
+}
+
+// parseDocument parses the next document, reporting false at the end of the
+// stream.
+func (p *yamlParser) parseDocument() (interface{}, bool, error) {
+	p.anchors = make(map[string]interface{})
+	p.anchorSizes = make(map[string]int)
+	p.sizes = make(map[collectionKey]int)
+	explicit := false
+	for ; p.line < len(p.lines); p.line++ {
+		line := p.lines[p.line]
+		if strings.HasPrefix(line, "%") || isBlankYAML(line) {
+			continue
+		}
+		if docMarker(line, "---") {
+			// Content may follow the marker on the same line.
+			explicit = true
+			p.lines[p.line] = "   " + line[3:]
+			if isBlankYAML(p.lines[p.line]) {
+				p.line++
+			}
+		}
+		break
+	}
+	if p.line >= len(p.lines) && !explicit {
+		return nil, false, nil
 	}
-	parser := &yamlParser{tokens: tokens}
-	node, err := parser.parseNode(0)
+
+	var doc interface{}
+	ok, err := p.skipBlank()
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	if ok {
+		indent := indentOf(p.lines[p.line])
+		if doc, err = p.parseNode(indent, -1); err != nil {
+			return nil, false, err
+		}
+		ok, err := p.skipBlank()
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			line := p.lines[p.line]
+			return nil, false, p.errorf(p.line, indentOf(line), "unexpected content after the document")
+		}
+	}
+	if p.line < len(p.lines) && docMarker(p.lines[p.line], "...") {
+		p.lines[p.line] = ""
 	}
-	return node, nil
+	return doc, true, nil
 }
 
-func tokenizeYAML(input string) ([]yamlToken, error) {
-	var tokens []yamlToken
-	lines := strings.Split(input, "\n")
-	for idx, raw := range lines {
-		line := strings.TrimRight(raw, "\r")
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+// skipBlank moves past blank and comment lines, reporting false at the end
+// of the document.
+func (p *yamlParser) skipBlank() (bool, error) {
+	for ; p.line < len(p.lines); p.line++ {
+		line := p.lines[p.line]
+		if docMarker(line, "---") || docMarker(line, "...") {
+			return false, nil
+		}
+		if isBlankYAML(line) {
 			continue
 		}
-		indent := countIndent(line)
-		if indent%2 != 0 {
-			return nil, fmt.Errorf("line %d: indentation must be multiples of two spaces", idx+1)
+		indent := indentOf(line)
+		if line[indent] == '\t' {
+			return false, p.errorf(p.line, indent, "tabs are not allowed in indentation")
 		}
-		tokens = append(tokens, yamlToken{
-			indent: indent,
-			text:   strings.TrimSpace(line),
-			line:   idx + 1,
-		})
+		return true, nil
 	}
-	return tokens, nil
+	return false, nil
 }
 
-func countIndent(line string) int {
-	count := 0
-	for _, r := range line {
-		if r == ' ' {
-			count++
+// parseNode parses the node starting at column indent of the current line.
+// parent is the indentation of the enclosing block, which continuation lines
+// of scalars must exceed.
+func (p *yamlParser) parseNode(indent, parent int) (interface{}, error) {
+	text := p.lines[p.line][indent:]
+	switch {
+	case isSequenceItem(text):
+		return p.parseSequence(indent)
+	case text[0] == '&':
+		name, end := anchorName(text)
+		if name == "" {
+			return nil, p.errorf(p.line, indent, "anchor needs a name")
+		}
+		var value interface{}
+		var err error
+		if p.blankFrom(indent + end) {
+			p.line++
+			value, err = p.parseNested(parent, false)
 		} else {
-			break
+			col := p.consume(indent + end)
+			value, err = p.parseNode(col, parent)
+		}
+		if err != nil {
+			return nil, err
 		}
+		p.anchor(name, value)
+		return value, nil
+	case mappingColon(text) >= 0:
+		return p.parseMap(indent)
 	}
-	return count
+	return p.parseValue(indent, parent)
 }
 
-func (p *yamlParser) parseNode(indent int) (interface{}, error) {
-	if p.index >= len(p.tokens) {
-		return nil, nil
+// parseNested parses the block that follows a "key:" or "-" with nothing
+// after it, or returns null when there is none. indentless allows a
+// sequence at the parent's own indentation, as YAML does for map values.
+func (p *yamlParser) parseNested(parent int, indentless bool) (interface{}, error) {
+	ok, err := p.skipBlank()
+	if err != nil || !ok {
+		return nil, err
 	}
-	tok := p.tokens[p.index]
-	if tok.indent < indent {
-		return nil, nil
+	line := p.lines[p.line]
+	indent := indentOf(line)
+	if indent > parent {
+		return p.parseNode(indent, parent)
 	}
-	if strings.HasPrefix(tok.text, "- ") {
+	if indentless && indent == parent && isSequenceItem(line[indent:]) {
 		return p.parseSequence(indent)
 	}
-	return p.parseMap(indent)
+	return nil, nil
 }
 
 func (p *yamlParser) parseMap(indent int) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
-	for p.index < len(p.tokens) {
-		tok := p.tokens[p.index]
-		if tok.indent < indent {
-			break
+	var merges []interface{}
+	var mergeLine int
+	for {
+		ok, err := p.skipBlank()
+		if err != nil {
+			return nil, err
 		}
-		if tok.indent > indent {
-			return nil, fmt.Errorf("line %d: unexpected indentation", tok.line)
+		if !ok {
+			break
 		}
-		if strings.HasPrefix(tok.text, "- ") {
+		line := p.lines[p.line]
+		current := indentOf(line)
+		if current < indent || (current == indent && isSequenceItem(line[current:])) {
 			break
 		}
-		key, value, hasValue := splitKeyValue(tok.text)
-		if key == "" {
-			return nil, fmt.Errorf("line %d: expected key", tok.line)
+		if current > indent {
+			return nil, p.errorf(p.line, current, "unexpected indentation")
 		}
-		p.index++
-		if hasValue {
-			if value == "" {
-				val, err := p.parseNode(indent + 2)
-				if err != nil {
-					return nil, err
-				}
-				result[key] = val
-			} else {
-				result[key] = parseScalar(value)
+
+		keyLine := p.line
+		key, valueCol, err := p.parseKey(current)
+		if err != nil {
+			return nil, err
+		}
+		leave := p.enter(key, keyLine, current)
+		value, err := p.parseValue(valueCol, indent)
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			merges = append(merges, value)
+			mergeLine = keyLine
+			continue
+		}
+		if _, dup := result[key]; dup {
+			return nil, p.errorf(keyLine, current, "duplicate key %q", key)
+		}
+		result[key] = value
+	}
+
+	// Keys set explicitly win over merged ones, whatever their order.
+	for _, merge := range merges {
+		sources := []interface{}{merge}
+		if list, ok := merge.([]interface{}); ok {
+			sources = list
+		}
+		for _, source := range sources {
+			fields, ok := source.(map[string]interface{})
+			if !ok {
+				return nil, p.errorf(mergeLine, indent, "<< needs a mapping or a list of mappings")
 			}
-		} else {
-			val, err := p.parseNode(indent + 2)
-			if err != nil {
-				return nil, err
+			for key, value := range fields {
+				if _, set := result[key]; !set {
+					result[key] = value
+				}
 			}
-			result[key] = val
 		}
 	}
 	return result, nil
 }
 
+// parseKey reads the key of a "key: value" line starting at col and returns
+// the column just past the colon.
+func (p *yamlParser) parseKey(col int) (string, int, error) {
+	line := p.lines[p.line]
+	text := line[col:]
+	switch text[0] {
+	case '"', '\'':
+		cur := &yamlCursor{p: p, line: p.line, col: col}
+		key, err := cur.quoted(false)
+		if err != nil {
+			return "", 0, err
+		}
+		cur.skipSpaces()
+		if cur.peek() != ':' {
+			return "", 0, p.errorf(cur.line, cur.col, "expected ':' after key")
+		}
+		return key, cur.col + 1, nil
+	case '?':
+		return "", 0, p.errorf(p.line, col, "complex keys are not supported")
+	}
+	colon := mappingColon(text)
+	if colon < 0 {
+		return "", 0, p.errorf(p.line, col, "expected \"key: value\"")
+	}
+	key := strings.TrimSpace(text[:colon])
+	if key == "" {
+		return "", 0, p.errorf(p.line, col, "empty key")
+	}
+	return key, col + colon + 1, nil
+}
+
 func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
-	var seq []interface{}
-	for p.index < len(p.tokens) {
-		tok := p.tokens[p.index]
-		if tok.indent < indent || !strings.HasPrefix(tok.text, "- ") {
+	seq := []interface{}{}
+	for {
+		ok, err := p.skipBlank()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
 			break
 		}
-		line := strings.TrimSpace(tok.text[2:])
-		p.index++
-		if line == "" {
-			val, err := p.parseNode(indent + 2)
-			if err != nil {
-				return nil, err
-			}
-			seq = append(seq, val)
+		line := p.lines[p.line]
+		current := indentOf(line)
+		if current > indent {
+			return nil, p.errorf(p.line, current, "unexpected indentation")
+		}
+		if current < indent || !isSequenceItem(line[current:]) {
+			break
+		}
+
+		var item interface{}
+		leave := p.enter(fmt.Sprintf("[%d]", len(seq)), p.line, current)
+		if p.blankFrom(current + 1) {
+			p.line++
+			item, err = p.parseNested(indent, false)
+		} else {
+			col := p.consume(current + 1)
+			item, err = p.parseNode(col, indent)
+		}
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+	}
+	return seq, nil
+}
+
+// parseValue parses the value starting at or after col on the current line:
+// an anchor, alias, block scalar, flow collection, quoted or plain scalar,
+// or, when the line is empty from col on, the nested block below it. parent
+// is the indentation of the enclosing block.
+func (p *yamlParser) parseValue(col, parent int) (interface{}, error) {
+	line := p.lines[p.line]
+	for col < len(line) && (line[col] == ' ' || line[col] == '\t') {
+		col++
+	}
+	if p.blankFrom(col) {
+		p.line++
+		return p.parseNested(parent, true)
+	}
+
+	switch c := line[col]; c {
+	case '&':
+		name, end := anchorName(line[col:])
+		if name == "" {
+			return nil, p.errorf(p.line, col, "anchor needs a name")
+		}
+		value, err := p.parseValue(col+end, parent)
+		if err != nil {
+			return nil, err
+		}
+		p.anchor(name, value)
+		return value, nil
+	case '*':
+		name, end := anchorName(line[col:])
+		value, err := p.alias(name, p.line, col)
+		if err != nil {
+			return nil, err
+		}
+		if !p.blankFrom(col + end) {
+			return nil, p.errorf(p.line, col+end, "unexpected content after alias")
+		}
+		p.line++
+		return value, nil
+	case '|', '>':
+		return p.blockScalar(col, parent)
+	case '[', '{', '"', '\'':
+		cur := &yamlCursor{p: p, line: p.line, col: col}
+		value, err := cur.node()
+		if err != nil {
+			return nil, err
+		}
+		cur.skipSpaces()
+		if !p.blankAt(cur.line, cur.col) {
+			return nil, p.errorf(cur.line, cur.col, "unexpected content after value")
+		}
+		p.line = cur.line + 1
+		return value, nil
+	case '!':
+		return nil, p.errorf(p.line, col, "tags are not supported")
+	case '@', '`':
+		return nil, p.errorf(p.line, col, "%q cannot start a plain value", c)
+	}
+	if isSequenceItem(line[col:]) {
+		return nil, p.errorf(p.line, col, "a sequence cannot start here")
+	}
+	return p.plainScalar(col, parent)
+}
+
+// plainScalar reads an unquoted scalar, folding continuation lines indented
+// past parent into it.
+func (p *yamlParser) plainScalar(col, parent int) (interface{}, error) {
+	line := p.lines[p.line]
+	text := strings.TrimSpace(stripComment(line[col:]))
+	if hasComment(line[col:]) {
+		p.line++
+		return parseScalar(text), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(text)
+	breaks := 0
+	for p.line++; p.line < len(p.lines); p.line++ {
+		next := p.lines[p.line]
+		if strings.TrimSpace(next) == "" {
+			breaks++
 			continue
 		}
-		if key, val, hasValue := splitKeyValue(line); hasValue {
-			item := make(map[string]interface{})
-			if val == "" {
-				sub, err := p.parseNode(indent + 2)
-				if err != nil {
-					return nil, err
-				}
-				item[key] = sub
+		indent := indentOf(next)
+		trimmed := next[indent:]
+		if indent <= parent || docMarker(next, "---") || docMarker(next, "...") || trimmed[0] == '#' {
+			break
+		}
+		if mappingColon(trimmed) >= 0 {
+			return nil, p.errorf(p.line, indent+mappingColon(trimmed), "mapping values are not allowed here")
+		}
+		if breaks == 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(strings.Repeat("\n", breaks))
+		}
+		breaks = 0
+		b.WriteString(strings.TrimSpace(stripComment(trimmed)))
+		if hasComment(trimmed) {
+			p.line++
+			break
+		}
+	}
+	if b.Len() == len(text) {
+		return parseScalar(text), nil
+	}
+	return b.String(), nil
+}
+
+// blockScalar reads a "|" (literal) or ">" (folded) scalar whose header
+// starts at col.
+func (p *yamlParser) blockScalar(col, parent int) (interface{}, error) {
+	header := p.lines[p.line][col:]
+	folded := header[0] == '>'
+	chomp := byte(0)
+	explicit := 0
+	i := 1
+	for ; i < len(header) && i < 3; i++ {
+		c := header[i]
+		if (c == '-' || c == '+') && chomp == 0 {
+			chomp = c
+		} else if c >= '1' && c <= '9' && explicit == 0 {
+			explicit = int(c - '0')
+		} else {
+			break
+		}
+	}
+	if !p.blankFrom(col + i) {
+		return nil, p.errorf(p.line, col+i, "invalid block scalar header")
+	}
+	p.line++
+
+	// The content is indented by the explicit amount past the parent, or as
+	// far as its first non-blank line.
+	indent, detected := 0, explicit > 0
+	if detected {
+		indent = explicit
+		if parent > 0 {
+			indent += parent
+		}
+	}
+	var lines []string
+	for ; p.line < len(p.lines); p.line++ {
+		line := p.lines[p.line]
+		if docMarker(line, "---") || docMarker(line, "...") {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			if detected && len(line) > indent {
+				lines = append(lines, line[indent:])
 			} else {
-				item[key] = parseScalar(val)
+				lines = append(lines, "")
 			}
-			extra, err := p.parseMap(indent + 2)
-			if err != nil {
-				return nil, err
+			continue
+		}
+		current := indentOf(line)
+		if !detected {
+			if current <= parent {
+				break
 			}
-			for k, v := range extra {
-				item[k] = v
+			indent, detected = current, true
+		}
+		if current < indent {
+			break
+		}
+		lines = append(lines, line[indent:])
+	}
+
+	trailing := 0
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var body string
+	if folded {
+		body = foldLines(lines)
+	} else {
+		body = strings.Join(lines, "\n")
+	}
+	switch {
+	case chomp == '-' || body == "" && chomp != '+':
+	case chomp == '+':
+		body += strings.Repeat("\n", trailing+1)
+	default:
+		body += "\n"
+	}
+	return body, nil
+}
+
+// foldLines joins the lines of a folded block scalar: line breaks between
+// text lines become spaces, blank lines become line breaks, and more
+// indented lines keep theirs.
+func foldLines(lines []string) string {
+	var b strings.Builder
+	last := -1
+	for i, line := range lines {
+		if i > 0 {
+			switch {
+			case line == "":
+				b.WriteByte('\n')
+			case lines[i-1] != "":
+				if moreIndented(line) || moreIndented(lines[i-1]) {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte(' ')
+				}
+			case last >= 0 && (moreIndented(line) || moreIndented(lines[last])):
+				b.WriteByte('\n')
 			}
-			seq = append(seq, item)
-		} else {
-			seq = append(seq, parseScalar(line))
 		}
+		if line != "" {
+			last = i
+		}
+		b.WriteString(line)
 	}
-	return seq, nil
+	return b.String()
+}
+
+func moreIndented(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+// consume blanks the current line up to col, along with the spaces after
+// it, and returns the column where the rest starts.
+func (p *yamlParser) consume(col int) int {
+	line := p.lines[p.line]
+	for col < len(line) && (line[col] == ' ' || line[col] == '\t') {
+		col++
+	}
+	p.lines[p.line] = strings.Repeat(" ", col) + line[col:]
+	return col
+}
+
+// blankFrom reports whether the current line holds nothing but whitespace
+// and comments from col on.
+func (p *yamlParser) blankFrom(col int) bool {
+	return p.blankAt(p.line, col)
+}
+
+func (p *yamlParser) blankAt(line, col int) bool {
+	text := p.lines[line]
+	if col >= len(text) {
+		return true
+	}
+	rest := strings.TrimLeft(text[col:], " \t")
+	return rest == "" || (rest[0] == '#' && (col == 0 || len(rest) < len(text[col:]) || text[col-1] == ' ' || text[col-1] == '\t'))
+}
+
+func isBlankYAML(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || trimmed[0] == '#'
+}
+
+func docMarker(line, marker string) bool {
+	return strings.HasPrefix(line, marker) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t')
+}
+
+func indentOf(line string) int {
+	count := 0
+	for count < len(line) && line[count] == ' ' {
+		count++
+	}
+	return count
+}
+
+// isSequenceItem reports whether a line starts a "- " sequence entry.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "-\t")
+}
+
+// anchorName reads the name after a leading & or * and returns the offset
+// just past it.
+func anchorName(text string) (string, int) {
+	end := 1
+	for end < len(text) && !strings.ContainsRune(" \t,[]{}", rune(text[end])) {
+		end++
+	}
+	return text[1:end], end
+}
+
+// mappingColon returns the index of the colon separating a key from its
+// value, or -1. Like YAML, it ignores colons inside a quoted key and colons
+// not followed by whitespace, so scalars such as "org.example:artifact" stay
+// intact, and it stops at a comment.
+func mappingColon(line string) int {
+	if line == "" {
+		return -1
+	}
+	switch line[0] {
+	case '"', '\'':
+		quote := line[0]
+		for i := 1; i < len(line); i++ {
+			switch {
+			case quote == '"' && line[i] == '\\':
+				i++
+			case line[i] == quote && quote == '\'' && i+1 < len(line) && line[i+1] == '\'':
+				i++
+			case line[i] == quote:
+				rest := strings.TrimLeft(line[i+1:], " \t")
+				if strings.HasPrefix(rest, ":") && (len(rest) == 1 || rest[1] == ' ' || rest[1] == '\t') {
+					return len(line) - len(rest)
+				}
+				return -1
+			}
+		}
+		return -1
+	case '[', '{', '|', '>', '*', '&', '!', '#':
+		return -1
+	}
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '#' && i > 0 && (line[i-1] == ' ' || line[i-1] == '\t'):
+			return -1
+		case line[i] == ':' && (i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\t'):
+			return i
+		}
+	}
+	return -1
 }
 
-func splitKeyValue(line string) (string, string, bool) {
-	colon := strings.Index(line, ":")
-	if colon == -1 {
-		key := strings.Trim(line, ` "'`)
-		return key, "", false
+// stripComment cuts a " #" comment off a plain scalar.
+func stripComment(text string) string {
+	for i := 1; i < len(text); i++ {
+		if text[i] == '#' && (text[i-1] == ' ' || text[i-1] == '\t') {
+			return text[:i]
+		}
 	}
-	key := strings.TrimSpace(line[:colon])
-	key = strings.Trim(key, `"'`)
-	value := strings.TrimSpace(line[colon+1:])
-	return key, value, true
+	return text
 }
 
+func hasComment(text string) bool {
+	return len(stripComment(text)) < len(text)
+}
+
+// parseScalar resolves a plain scalar to a bool, null, integer or float, or
+// leaves it a string.
 func parseScalar(value string) interface{} {
-	lower := strings.ToLower(value)
-	switch lower {
+	switch strings.ToLower(value) {
 	case "true":
 		return true
 	case "false":
 		return false
-	}
-	if strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) && len(value) >= 2 {
-		return strings.Trim(value, `"`)
-	}
-	if strings.HasPrefix(value, `'`) && strings.HasSuffix(value, `'`) && len(value) >= 2 {
-		return strings.Trim(value, `'`)
+	case "", "null", "~":
+		return nil
 	}
 	if i, err := strconv.Atoi(value); err == nil {
 		return i
 	}
+	if c := value[0]; (c >= '0' && c <= '9') || c == '-' || c == '+' || c == '.' {
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			// The source text is kept where JSON can carry it, so a version
+			// such as 1.20 does not come back as 1.2.
+			if jsonNumber.MatchString(value) {
+				return json.Number(value)
+			}
+			return f
+		}
+	}
 	return value
 }
+
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// yamlCursor reads flow collections and quoted scalars, which may span
+// lines.
+type yamlCursor struct {
+	p         *yamlParser
+	line, col int
+}
+
+func (c *yamlCursor) peek() byte {
+	text := c.p.lines[c.line]
+	if c.col >= len(text) {
+		return 0
+	}
+	return text[c.col]
+}
+
+func (c *yamlCursor) errorf(format string, args ...interface{}) error {
+	return c.p.errorf(c.line, c.col, format, args...)
+}
+
+func (c *yamlCursor) skipSpaces() {
+	text := c.p.lines[c.line]
+	for c.col < len(text) && (text[c.col] == ' ' || text[c.col] == '\t') {
+		c.col++
+	}
+}
+
+// skip moves past whitespace, comments and line breaks inside a flow
+// collection.
+func (c *yamlCursor) skip(open *yamlCursor) error {
+	for {
+		c.skipSpaces()
+		if !c.p.blankAt(c.line, c.col) {
+			return nil
+		}
+		if c.line+1 >= len(c.p.lines) || docMarker(c.p.lines[c.line+1], "---") || docMarker(c.p.lines[c.line+1], "...") {
+			return open.errorf("unclosed %q", open.peek())
+		}
+		c.line++
+		c.col = 0
+	}
+}
+
+// node reads a flow node: a collection, a quoted or plain scalar, an alias,
+// or an anchored node.
+func (c *yamlCursor) node() (interface{}, error) {
+	switch c.peek() {
+	case '[':
+		return c.sequence()
+	case '{':
+		return c.mapping()
+	case '"', '\'':
+		s, err := c.quoted(true)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case '*', '&':
+		start := *c
+		name, end := anchorName(c.p.lines[c.line][c.col:])
+		if name == "" {
+			return nil, c.errorf("anchor needs a name")
+		}
+		c.col += end
+		if start.peek() == '*' {
+			return c.p.alias(name, start.line, start.col)
+		}
+		c.skipSpaces()
+		value, err := c.node()
+		if err != nil {
+			return nil, err
+		}
+		c.p.anchor(name, value)
+		return value, nil
+	case '!':
+		return nil, c.errorf("tags are not supported")
+	}
+	text := c.plain()
+	if text == "" {
+		return nil, c.errorf("expected a value")
+	}
+	return parseScalar(text), nil
+}
+
+// plain reads a plain scalar inside a flow collection, which ends at a flow
+// indicator, a ": " or a comment.
+func (c *yamlCursor) plain() string {
+	text := c.p.lines[c.line]
+	start := c.col
+	for ; c.col < len(text); c.col++ {
+		ch := text[c.col]
+		if strings.IndexByte(",[]{}", ch) >= 0 {
+			break
+		}
+		if ch == ':' && (c.col+1 == len(text) || strings.IndexByte(" \t,[]{}", text[c.col+1]) >= 0) {
+			break
+		}
+		if ch == '#' && c.col > start && (text[c.col-1] == ' ' || text[c.col-1] == '\t') {
+			break
+		}
+	}
+	return strings.TrimSpace(text[start:c.col])
+}
+
+func (c *yamlCursor) sequence() (interface{}, error) {
+	open := *c
+	c.col++
+	seq := []interface{}{}
+	for {
+		if err := c.skip(&open); err != nil {
+			return nil, err
+		}
+		if c.peek() == ']' {
+			c.col++
+			return seq, nil
+		}
+		leave := c.p.enter(fmt.Sprintf("[%d]", len(seq)), c.line, c.col)
+		item, err := c.node()
+		leave()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.skip(&open); err != nil {
+			return nil, err
+		}
+		// A "key: value" entry is a single-pair mapping.
+		if c.peek() == ':' {
+			key, ok := item.(string)
+			if !ok {
+				key = fmt.Sprint(item)
+			}
+			c.col++
+			value, err := c.entryValue(&open)
+			if err != nil {
+				return nil, err
+			}
+			item = map[string]interface{}{key: value}
+		}
+		seq = append(seq, item)
+		if err := c.separator(&open, ']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *yamlCursor) mapping() (interface{}, error) {
+	open := *c
+	c.col++
+	result := make(map[string]interface{})
+	for {
+		if err := c.skip(&open); err != nil {
+			return nil, err
+		}
+		if c.peek() == '}' {
+			c.col++
+			return result, nil
+		}
+		keyAt := *c
+		key, err := c.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := c.skip(&open); err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if c.peek() == ':' {
+			c.col++
+			leave := c.p.enter(key, keyAt.line, keyAt.col)
+			value, err = c.entryValue(&open)
+			leave()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, dup := result[key]; dup {
+			return nil, keyAt.errorf("duplicate key %q", key)
+		}
+		result[key] = value
+		if err := c.separator(&open, '}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// key reads a flow mapping key, which must be a scalar.
+func (c *yamlCursor) key() (string, error) {
+	switch c.peek() {
+	case '"', '\'':
+		return c.quoted(true)
+	case '[', '{':
+		return "", c.errorf("collection keys are not supported")
+	}
+	key := c.plain()
+	if key == "" {
+		return "", c.errorf("expected a key")
+	}
+	return key, nil
+}
+
+// entryValue reads the value after a ':' in a flow collection, which is
+// null when omitted.
+func (c *yamlCursor) entryValue(open *yamlCursor) (interface{}, error) {
+	if err := c.skip(open); err != nil {
+		return nil, err
+	}
+	if ch := c.peek(); ch == ',' || ch == '}' || ch == ']' {
+		return nil, nil
+	}
+	return c.node()
+}
+
+func (c *yamlCursor) separator(open *yamlCursor, closer byte) error {
+	if err := c.skip(open); err != nil {
+		return err
+	}
+	switch c.peek() {
+	case ',':
+		c.col++
+		return nil
+	case closer:
+		return nil
+	}
+	return c.errorf("expected ',' or %q", closer)
+}
+
+// quoted reads a single- or double-quoted scalar. Line breaks inside it fold
+// to spaces, and blank lines to line breaks, when multiline is set.
+func (c *yamlCursor) quoted(multiline bool) (string, error) {
+	open := *c
+	quote := c.peek()
+	c.col++
+	var b strings.Builder
+	for {
+		text := c.p.lines[c.line]
+		if c.col >= len(text) {
+			if !multiline {
+				return "", open.errorf("unterminated quoted string")
+			}
+			if err := c.foldBreak(&b, &open); err != nil {
+				return "", err
+			}
+			continue
+		}
+		ch := text[c.col]
+		switch {
+		case ch == quote && quote == '\'' && c.col+1 < len(text) && text[c.col+1] == '\'':
+			b.WriteByte('\'')
+			c.col += 2
+		case ch == quote:
+			c.col++
+			return b.String(), nil
+		case ch == '\\' && quote == '"':
+			if c.col+1 >= len(text) {
+				// An escaped line break joins the lines without a space.
+				if !multiline || c.line+1 >= len(c.p.lines) {
+					return "", open.errorf("unterminated quoted string")
+				}
+				c.line++
+				c.col = 0
+				c.skipSpaces()
+				continue
+			}
+			if err := c.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(text[c.col:])
+			b.WriteRune(r)
+			c.col += size
+		}
+	}
+}
+
+// foldBreak handles a line break inside a quoted scalar.
+func (c *yamlCursor) foldBreak(b *strings.Builder, open *yamlCursor) error {
+	trimmed := strings.TrimRight(b.String(), " \t")
+	b.Reset()
+	b.WriteString(trimmed)
+	breaks := 0
+	for {
+		c.line++
+		if c.line >= len(c.p.lines) || docMarker(c.p.lines[c.line], "---") || docMarker(c.p.lines[c.line], "...") {
+			return open.errorf("unterminated quoted string")
+		}
+		if strings.TrimSpace(c.p.lines[c.line]) != "" {
+			break
+		}
+		breaks++
+	}
+	if breaks == 0 {
+		b.WriteByte(' ')
+	} else {
+		b.WriteString(strings.Repeat("\n", breaks))
+	}
+	c.col = 0
+	c.skipSpaces()
+	return nil
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// escape decodes the escape sequence at the cursor in a double-quoted
+// scalar.
+func (c *yamlCursor) escape(b *strings.Builder) error {
+	text := c.p.lines[c.line]
+	code := text[c.col+1]
+	if s, ok := yamlEscapes[code]; ok {
+		b.WriteString(s)
+		c.col += 2
+		return nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[code]
+	if digits == 0 {
+		return c.errorf("invalid escape \\%c", code)
+	}
+	start := c.col + 2
+	if start+digits > len(text) {
+		return c.errorf("invalid escape \\%c", code)
+	}
+	n, err := strconv.ParseUint(text[start:start+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(n)) {
+		return c.errorf("invalid escape \\%s", text[c.col+1:start+digits])
+	}
+	b.WriteRune(rune(n))
+	c.col = start + digits
+	return nil
+}
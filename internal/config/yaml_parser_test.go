@@ -1,7 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -46,3 +50,195 @@ items:
 		t.Fatalf("unexpected first item: %+v", out["items"][0])
 	}
 }
+
+func TestParseYAMLScalarsWithColons(t *testing.T) {
+	data := []byte(`
+deps:
+  - "org.example:artifact"
+  - org.example:other
+url: http://example.com
+`)
+
+	var out struct {
+		Deps []string `json:"deps"`
+		URL  string   `json:"url"`
+	}
+	if err := decodeYAML(data, &out); err != nil {
+		t.Fatalf("decodeYAML error: %v", err)
+	}
+	if !reflect.DeepEqual(out.Deps, []string{"org.example:artifact", "org.example:other"}) {
+		t.Fatalf("unexpected deps: %#v", out.Deps)
+	}
+	if out.URL != "http://example.com" {
+		t.Fatalf("unexpected url: %q", out.URL)
+	}
+}
+
+func TestParseYAMLNestedBlocks(t *testing.T) {
+	data := []byte(`
+# team config
+ignoreDirs:
+- tmp
+- notes
+categories:
+  - when:
+      min: 1
+    name: First
+  -
+    name: Second
+thresholds: []
+extra: {}
+docker: null
+`)
+
+	root, err := parseYAML(data)
+	if err != nil {
+		t.Fatalf("parseYAML error: %v", err)
+	}
+	want := map[string]interface{}{
+		"ignoreDirs": []interface{}{"tmp", "notes"},
+		"categories": []interface{}{
+			map[string]interface{}{"when": map[string]interface{}{"min": 1}, "name": "First"},
+			map[string]interface{}{"name": "Second"},
+		},
+		"thresholds": []interface{}{},
+		"extra":      map[string]interface{}{},
+		"docker":     nil,
+	}
+	if !reflect.DeepEqual(root, want) {
+		t.Fatalf("unexpected result:\n got %#v\nwant %#v", root, want)
+	}
+}
+
+func TestParseYAMLFlowAndQuoting(t *testing.T) {
+	data := []byte(`
+ignoreDirs: [tmp, "out dir", 'it''s']   # inline comment
+analyzers: {git: false, lang: true}
+nested: [1, {k: [a, b]},
+  last]
+escaped: "tab\there \u00e9 \"quoted\""
+hash: "x # kept" # dropped
+`)
+
+	root, err := parseYAML(data)
+	if err != nil {
+		t.Fatalf("parseYAML error: %v", err)
+	}
+	want := map[string]interface{}{
+		"ignoreDirs": []interface{}{"tmp", "out dir", "it's"},
+		"analyzers":  map[string]interface{}{"git": false, "lang": true},
+		"nested":     []interface{}{1, map[string]interface{}{"k": []interface{}{"a", "b"}}, "last"},
+		"escaped":    "tab\there \u00e9 \"quoted\"",
+		"hash":       "x # kept",
+	}
+	if !reflect.DeepEqual(root, want) {
+		t.Fatalf("unexpected result:\n got %#v\nwant %#v", root, want)
+	}
+}
+
+func TestParseYAMLBlockScalarsAnchorsAndDocuments(t *testing.T) {
+	data := []byte(`
+defaults: &defaults
+   points: 2
+   note: |
+     first line
+       indented
+
+     last
+rules:
+   - <<: *defaults
+     summary: >-
+       folded
+       text
+   - *defaults
+---
+extra: true
+defaults:
+   points: 3
+`)
+
+	root, err := parseYAML(data)
+	if err != nil {
+		t.Fatalf("parseYAML error: %v", err)
+	}
+	defaults := map[string]interface{}{"points": 2, "note": "first line\n  indented\n\nlast\n"}
+	want := map[string]interface{}{
+		"defaults": map[string]interface{}{"points": 3, "note": defaults["note"]},
+		"rules": []interface{}{
+			map[string]interface{}{"points": 2, "note": defaults["note"], "summary": "folded text"},
+			defaults,
+		},
+		"extra": true,
+	}
+	if !reflect.DeepEqual(root, want) {
+		t.Fatalf("unexpected result:\n got %#v\nwant %#v", root, want)
+	}
+}
+
+func TestParseYAMLErrorPositions(t *testing.T) {
+	cases := map[string]string{
+		"a: 1\n  b: 2\n":         "line 2, column 4: mapping values are not allowed here",
+		"a:\n\t- x\n":            "line 2, column 1: tabs are not allowed in indentation",
+		"a: [1, 2\n":             "line 1, column 4: unclosed '['",
+		"a: \"bad \\q\"\n":       "line 1, column 9: invalid escape \\q",
+		"a: *missing\n":          "line 1, column 4: unknown alias \"*missing\"",
+		"a: 1\nb: 2\na: 3\n":     "line 3, column 1: duplicate key \"a\"",
+		"a:\n    b: 1\n  c: 2\n": "line 3, column 3: unexpected indentation",
+	}
+	for input, want := range cases {
+		_, err := parseYAML([]byte(input))
+		if err == nil || err.Error() != want {
+			t.Errorf("parseYAML(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestParseYAMLCapsAliasExpansion(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("a0: &a0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i < 10; i++ {
+		fmt.Fprintf(&b, "a%d: &a%d [", i, i)
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "*a%d", i-1)
+		}
+		b.WriteString("]\n")
+	}
+	_, err := parseYAML([]byte(b.String()))
+	want := "line 5, column 45: aliases expand to more than 100000 nodes"
+	if err == nil || err.Error() != want {
+		t.Fatalf("parseYAML error = %v, want %q", err, want)
+	}
+
+	// Reusing an anchor many times is fine while the total stays small.
+	if _, err := parseYAML([]byte("a: &a [1, 2]\nb: [*a, *a, *a, *a]\nc: {x: *a, y: *a}\n")); err != nil {
+		t.Fatalf("parseYAML error: %v", err)
+	}
+}
+
+func FuzzParseYAML(f *testing.F) {
+	for _, seed := range [][]byte{
+		defaultLanguagesYAML, defaultAnalyzersYAML, defaultScoringYAML,
+		defaultProfilesYAML, defaultCIYAML, defaultFrameworksYAML,
+		[]byte("a: [1, {b: \"c\\td\"}, 'e''f']\n"),
+		[]byte("base: &b\n  x: 1\nother:\n  <<: *b\n  y: |\n    text\n"),
+		[]byte("---\na: 1\n...\n--- >-\n  folded\n"),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		root, err := parseYAML(data)
+		if err != nil {
+			var yerr *yamlError
+			if !errors.As(err, &yerr) || yerr.line < 1 || yerr.col < 1 {
+				t.Fatalf("error without a position: %v", err)
+			}
+			return
+		}
+		if _, err := json.Marshal(root); err != nil {
+			t.Fatalf("parsed value does not encode: %v", err)
+		}
+	})
+}
@@ -0,0 +1,354 @@
+// Package expr implements the small expression language used by scoring and
+// category rules, for example:
+//
+//	commits > 50 && !hasTests && "Go" in languages && ageDays > 365
+//
+// Values are booleans, numbers, strings and lists of strings. Expressions are
+// type-checked against the available variables before they are evaluated, and
+// errors carry the line and column they refer to.
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Type int
+
+const (
+	Bool Type = iota + 1
+	Number
+	String
+	List
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case List:
+		return "list"
+	}
+	return "invalid"
+}
+
+type Value struct {
+	Type Type
+	B    bool
+	N    float64
+	S    string
+	L    []string
+}
+
+func BoolValue(b bool) Value         { return Value{Type: Bool, B: b} }
+func NumberValue(n float64) Value    { return Value{Type: Number, N: n} }
+func StringValue(s string) Value     { return Value{Type: String, S: s} }
+func ListValue(items []string) Value { return Value{Type: List, L: items} }
+
+// Env holds the variables an expression is evaluated against.
+type Env map[string]Value
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Parse parses src without checking identifiers or types.
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, errorf(t.pos, "unexpected %s", describe(t))
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Compile parses src and checks that it only uses the given variables and
+// evaluates to want.
+func Compile(src string, vars map[string]Type, want Type) (*Expr, error) {
+	e, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	got, err := check(e.root, vars)
+	if err != nil {
+		return nil, err
+	}
+	if got != want {
+		return nil, errorf(e.root.position(), "expression is a %s, expected a %s", got, want)
+	}
+	return e, nil
+}
+
+// Eval evaluates the expression. Compiled expressions only fail at run time
+// on division by zero.
+func (e *Expr) Eval(env Env) (Value, error) {
+	return eval(e.root, env)
+}
+
+// EvalBool evaluates an expression compiled as a Bool.
+func (e *Expr) EvalBool(env Env) (bool, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	if v.Type != Bool {
+		return false, fmt.Errorf("expression is a %s, expected a bool", v.Type)
+	}
+	return v.B, nil
+}
+
+var functions = map[string]struct{}{"len": {}, "lower": {}}
+
+func check(n node, vars map[string]Type) (Type, error) {
+	switch n := n.(type) {
+	case *literal:
+		return n.value.Type, nil
+	case *ident:
+		t, ok := vars[n.name]
+		if !ok {
+			return 0, errorf(n.pos, "unknown identifier %q", n.name)
+		}
+		return t, nil
+	case *listLit:
+		for _, item := range n.items {
+			t, err := check(item, vars)
+			if err != nil {
+				return 0, err
+			}
+			if t != String {
+				return 0, errorf(item.position(), "list items must be strings, got %s", t)
+			}
+		}
+		return List, nil
+	case *unary:
+		t, err := check(n.x, vars)
+		if err != nil {
+			return 0, err
+		}
+		want := Bool
+		if n.op == "-" {
+			want = Number
+		}
+		if t != want {
+			return 0, errorf(n.pos, "operator %s expects a %s, got %s", n.op, want, t)
+		}
+		return want, nil
+	case *call:
+		if _, ok := functions[n.fn]; !ok {
+			return 0, errorf(n.pos, "unknown function %q", n.fn)
+		}
+		if len(n.args) != 1 {
+			return 0, errorf(n.pos, "%s expects 1 argument, got %d", n.fn, len(n.args))
+		}
+		t, err := check(n.args[0], vars)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case n.fn == "len" && (t == String || t == List):
+			return Number, nil
+		case n.fn == "lower" && t == String:
+			return String, nil
+		}
+		return 0, errorf(n.args[0].position(), "%s does not accept a %s", n.fn, t)
+	case *binary:
+		x, err := check(n.x, vars)
+		if err != nil {
+			return 0, err
+		}
+		y, err := check(n.y, vars)
+		if err != nil {
+			return 0, err
+		}
+		return checkBinary(n, x, y)
+	}
+	return 0, fmt.Errorf("unknown node %T", n)
+}
+
+func checkBinary(n *binary, x, y Type) (Type, error) {
+	mismatch := func() (Type, error) {
+		return 0, errorf(n.pos, "operator %s cannot combine %s and %s", n.op, x, y)
+	}
+	switch n.op {
+	case "&&", "||":
+		if x != Bool || y != Bool {
+			return mismatch()
+		}
+		return Bool, nil
+	case "==", "!=":
+		if x != y || x == List {
+			return mismatch()
+		}
+		return Bool, nil
+	case "<", "<=", ">", ">=":
+		if x != y || (x != Number && x != String) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "in":
+		if x != String || (y != List && y != String) {
+			return mismatch()
+		}
+		return Bool, nil
+	case "+":
+		if x != y || (x != Number && x != String) {
+			return mismatch()
+		}
+		return x, nil
+	default:
+		if x != Number || y != Number {
+			return mismatch()
+		}
+		return Number, nil
+	}
+}
+
+func eval(n node, env Env) (Value, error) {
+	switch n := n.(type) {
+	case *literal:
+		return n.value, nil
+	case *ident:
+		v, ok := env[n.name]
+		if !ok {
+			return Value{}, errorf(n.pos, "unknown identifier %q", n.name)
+		}
+		return v, nil
+	case *listLit:
+		items := make([]string, 0, len(n.items))
+		for _, item := range n.items {
+			v, err := eval(item, env)
+			if err != nil {
+				return Value{}, err
+			}
+			items = append(items, v.S)
+		}
+		return ListValue(items), nil
+	case *unary:
+		v, err := eval(n.x, env)
+		if err != nil {
+			return Value{}, err
+		}
+		if n.op == "-" {
+			return NumberValue(-v.N), nil
+		}
+		return BoolValue(!v.B), nil
+	case *call:
+		v, err := eval(n.args[0], env)
+		if err != nil {
+			return Value{}, err
+		}
+		if n.fn == "lower" {
+			return StringValue(strings.ToLower(v.S)), nil
+		}
+		if v.Type == List {
+			return NumberValue(float64(len(v.L))), nil
+		}
+		return NumberValue(float64(len([]rune(v.S)))), nil
+	case *binary:
+		return evalBinary(n, env)
+	}
+	return Value{}, fmt.Errorf("unknown node %T", n)
+}
+
+func evalBinary(n *binary, env Env) (Value, error) {
+	x, err := eval(n.x, env)
+	if err != nil {
+		return Value{}, err
+	}
+	// && and || short-circuit.
+	if (n.op == "&&" && !x.B) || (n.op == "||" && x.B) {
+		return BoolValue(x.B), nil
+	}
+	y, err := eval(n.y, env)
+	if err != nil {
+		return Value{}, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return BoolValue(y.B), nil
+	case "==":
+		return BoolValue(equal(x, y)), nil
+	case "!=":
+		return BoolValue(!equal(x, y)), nil
+	case "<", "<=", ">", ">=":
+		c := compare(x, y)
+		switch n.op {
+		case "<":
+			return BoolValue(c < 0), nil
+		case "<=":
+			return BoolValue(c <= 0), nil
+		case ">":
+			return BoolValue(c > 0), nil
+		}
+		return BoolValue(c >= 0), nil
+	case "in":
+		if y.Type == String {
+			return BoolValue(strings.Contains(y.S, x.S)), nil
+		}
+		for _, item := range y.L {
+			if item == x.S {
+				return BoolValue(true), nil
+			}
+		}
+		return BoolValue(false), nil
+	case "+":
+		if x.Type == String {
+			return StringValue(x.S + y.S), nil
+		}
+		return NumberValue(x.N + y.N), nil
+	case "-":
+		return NumberValue(x.N - y.N), nil
+	case "*":
+		return NumberValue(x.N * y.N), nil
+	case "/", "%":
+		if y.N == 0 || (n.op == "%" && int64(y.N) == 0) {
+			return Value{}, errorf(n.pos, "division by zero")
+		}
+		if n.op == "/" {
+			return NumberValue(x.N / y.N), nil
+		}
+		return NumberValue(float64(int64(x.N) % int64(y.N))), nil
+	}
+	return Value{}, errorf(n.pos, "unknown operator %s", n.op)
+}
+
+func equal(x, y Value) bool {
+	switch x.Type {
+	case Bool:
+		return x.B == y.B
+	case Number:
+		return x.N == y.N
+	}
+	return x.S == y.S
+}
+
+func compare(x, y Value) int {
+	if x.Type == String {
+		return strings.Compare(x.S, y.S)
+	}
+	switch {
+	case x.N < y.N:
+		return -1
+	case x.N > y.N:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package expr
+
+import "testing"
+
+var testVars = map[string]Type{
+	"commits":   Number,
+	"ageDays":   Number,
+	"hasTests":  Bool,
+	"license":   String,
+	"languages": List,
+}
+
+func TestEval(t *testing.T) {
+	env := Env{
+		"commits":   NumberValue(80),
+		"ageDays":   NumberValue(400),
+		"hasTests":  BoolValue(false),
+		"license":   StringValue("MIT"),
+		"languages": ListValue([]string{"Go", "Shell"}),
+	}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`commits > 50 && !hasTests && "Go" in languages && ageDays > 365`, true},
+		{`commits > 50 && hasTests`, false},
+		{`hasTests || commits >= 80`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`-(commits - 100) / 4 == 5`, true},
+		{`license in ["MIT", "Apache-2.0"] && len(languages) == 2`, true},
+		{`lower(license) == "mit" && "I" in license`, true},
+		{`!(commits > 50) || ageDays % 7 == 1`, true},
+	}
+	for _, tt := range tests {
+		e, err := Compile(tt.src, testVars, Bool)
+		if err != nil {
+			t.Fatalf("%s: compile error: %v", tt.src, err)
+		}
+		got, err := e.EvalBool(env)
+		if err != nil {
+			t.Fatalf("%s: eval error: %v", tt.src, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: expected %v, got %v", tt.src, tt.want, got)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`commits > `, `1:11: unexpected end of expression`},
+		{`comits > 5`, `1:1: unknown identifier "comits"`},
+		{"hasTests &&\n  commits", `1:10: operator && cannot combine bool and number`},
+		{"\"Go\" in license\n  && languages > 2", `2:16: operator > cannot combine list and number`},
+		{`commits + 1`, `1:9: expression is a number, expected a bool`},
+		{`"open`, `1:1: unterminated string`},
+		{`commits > 5 )`, `1:13: unexpected ")"`},
+		{`size(languages) > 1`, `1:1: unknown function "size"`},
+		{`commits @ 2`, `1:9: unexpected character '@'`},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.src, testVars, Bool)
+		if err == nil {
+			t.Fatalf("%q: expected error %q", tt.src, tt.want)
+		}
+		if err.Error() != tt.want {
+			t.Fatalf("%q: expected error %q, got %q", tt.src, tt.want, err.Error())
+		}
+	}
+}
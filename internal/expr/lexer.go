@@ -0,0 +1,142 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  Pos
+}
+
+// Pos is a 1-based line and column in the expression source.
+type Pos struct {
+	Line   int
+	Column int
+}
+
+// Error is a parse or type error at a position in the source.
+type Error struct {
+	Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+func errorf(pos Pos, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// operators are matched longest first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "!", "<", ">", "+", "-", "*", "/", "%", "(", ")", "[", "]", ","}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	line, col := 1, 1
+	advance := func(n int) {
+		for i := 0; i < n; i++ {
+			if runes[0] == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+			runes = runes[1:]
+		}
+	}
+
+	for len(runes) > 0 {
+		r := runes[0]
+		pos := Pos{Line: line, Column: col}
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+		case unicode.IsDigit(r) || (r == '.' && len(runes) > 1 && unicode.IsDigit(runes[1])):
+			n := 0
+			for n < len(runes) && (unicode.IsDigit(runes[n]) || runes[n] == '.' || runes[n] == '_') {
+				n++
+			}
+			text := string(runes[:n])
+			value, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, errorf(pos, "invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: value, pos: pos})
+			advance(n)
+		case r == '"' || r == '\'':
+			text, n, err := lexString(runes, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, pos: pos})
+			advance(n)
+		case unicode.IsLetter(r) || r == '_':
+			n := 0
+			for n < len(runes) && (unicode.IsLetter(runes[n]) || unicode.IsDigit(runes[n]) || runes[n] == '_') {
+				n++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[:n]), pos: pos})
+			advance(n)
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[:min(len(runes), 2)]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, errorf(pos, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: pos})
+			advance(len(op))
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: Pos{Line: line, Column: col}}), nil
+}
+
+// lexString reads a quoted string starting at runes[0] and returns its value
+// and the number of runes consumed.
+func lexString(runes []rune, pos Pos) (string, int, error) {
+	quote := runes[0]
+	var b strings.Builder
+	for i := 1; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == quote:
+			return b.String(), i + 1, nil
+		case r == '\n':
+			return "", 0, errorf(pos, "unterminated string")
+		case r == '\\' && i+1 < len(runes):
+			i++
+			switch runes[i] {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune(runes[i])
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, errorf(pos, "unterminated string")
+}
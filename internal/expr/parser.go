@@ -0,0 +1,207 @@
+package expr
+
+// node is an expression AST node.
+type node interface {
+	position() Pos
+}
+
+type literal struct {
+	pos   Pos
+	value Value
+}
+
+type ident struct {
+	pos  Pos
+	name string
+}
+
+type listLit struct {
+	pos   Pos
+	items []node
+}
+
+type unary struct {
+	pos Pos
+	op  string
+	x   node
+}
+
+type binary struct {
+	pos  Pos
+	op   string
+	x, y node
+}
+
+type call struct {
+	pos  Pos
+	fn   string
+	args []node
+}
+
+func (n *literal) position() Pos { return n.pos }
+func (n *ident) position() Pos   { return n.pos }
+func (n *listLit) position() Pos { return n.pos }
+func (n *unary) position() Pos   { return n.pos }
+func (n *binary) position() Pos  { return n.pos }
+func (n *call) position() Pos    { return n.pos }
+
+// bindingPower gives the precedence of each infix operator; higher binds
+// tighter.
+var bindingPower = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4, "in": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+const prefixPower = 7
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) (token, error) {
+	t := p.next()
+	if t.kind != tokOp || t.text != op {
+		return t, errorf(t.pos, "expected %q, found %s", op, describe(t))
+	}
+	return t, nil
+}
+
+// parseExpr is a Pratt parser: it parses a prefix expression, then folds in
+// infix operators that bind tighter than minPower.
+func (p *parser) parseExpr(minPower int) (node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		op, ok := infixOp(t)
+		if !ok || bindingPower[op] <= minPower {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(bindingPower[op])
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{pos: t.pos, op: op, x: left, y: right}
+	}
+}
+
+func infixOp(t token) (string, bool) {
+	switch t.kind {
+	case tokOp:
+		_, ok := bindingPower[t.text]
+		return t.text, ok
+	case tokIdent:
+		return t.text, t.text == "in"
+	}
+	return "", false
+}
+
+func (p *parser) parsePrefix() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literal{pos: t.pos, value: NumberValue(t.num)}, nil
+	case tokString:
+		return &literal{pos: t.pos, value: StringValue(t.text)}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return &literal{pos: t.pos, value: BoolValue(t.text == "true")}, nil
+		case "in":
+			return nil, errorf(t.pos, "unexpected %s", describe(t))
+		}
+		if next := p.peek(); next.kind == tokOp && next.text == "(" {
+			p.next()
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			return &call{pos: t.pos, fn: t.text, args: args}, nil
+		}
+		return &ident{pos: t.pos, name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "!", "-":
+			x, err := p.parseExpr(prefixPower)
+			if err != nil {
+				return nil, err
+			}
+			return &unary{pos: t.pos, op: t.text, x: x}, nil
+		case "(":
+			x, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listLit{pos: t.pos, items: items}, nil
+		}
+	}
+	return nil, errorf(t.pos, "unexpected %s", describe(t))
+}
+
+// parseList parses comma-separated expressions up to the closing token.
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if t := p.peek(); t.kind == tokOp && t.text == closing {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		t := p.next()
+		if t.kind == tokOp && t.text == closing {
+			return items, nil
+		}
+		if t.kind != tokOp || t.text != "," {
+			return nil, errorf(t.pos, "expected \",\" or %q, found %s", closing, describe(t))
+		}
+	}
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return "string " + quote(t.text)
+	case tokNumber:
+		return "number " + t.text
+	}
+	return quote(t.text)
+}
+
+func quote(s string) string {
+	return "\"" + s + "\""
+}
@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// Filter selects projects. Each non-empty field must match (values within a
+// field are alternatives); comparisons ignore case.
+type Filter struct {
+	Categories []string
+	Frameworks []string
+	Types      []string
+	Tags       []string
+	Owners     []string
+	Statuses   []string
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.Categories) == 0 && len(f.Frameworks) == 0 && len(f.Types) == 0 && len(f.Tags) == 0 &&
+		len(f.Owners) == 0 && len(f.Statuses) == 0
+}
+
+func (f Filter) Match(p *model.Project) bool {
+	if p == nil {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsFold(f.Categories, p.Category) {
+		return false
+	}
+	if len(f.Frameworks) > 0 && !overlapsFold(f.Frameworks, p.Metrics.Frameworks) {
+		return false
+	}
+	if len(f.Types) > 0 && !containsFold(f.Types, p.Metrics.ProjectType) {
+		return false
+	}
+	if len(f.Tags) > 0 && !overlapsFold(f.Tags, p.Tags) {
+		return false
+	}
+	if len(f.Owners) > 0 && !containsFold(f.Owners, p.Owner) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, p.Status) {
+		return false
+	}
+	return true
+}
+
+// Prune removes projects that do not match from the tree, along with any
+// directories left without a matching project beneath them. The root node is
+// always kept. An empty filter leaves the tree untouched.
+func Prune(root *model.Node, f Filter) *model.Node {
+	if root == nil || f.IsEmpty() {
+		return root
+	}
+	prune(root, f)
+	return root
+}
+
+func prune(node *model.Node, f Filter) bool {
+	if node.Project != nil && !f.Match(node.Project) {
+		node.Project = nil
+	}
+	kept := node.Children[:0]
+	for _, child := range node.Children {
+		if prune(child, f) {
+			kept = append(kept, child)
+		}
+	}
+	node.Children = kept
+	return node.Project != nil || len(node.Children) > 0
+}
+
+// Projects returns the matching projects in tree order.
+func Projects(root *model.Node, f Filter) []*model.Project {
+	var out []*model.Project
+	var visit func(node *model.Node)
+	visit = func(node *model.Node) {
+		if node == nil {
+			return
+		}
+		if node.Project != nil && f.Match(node.Project) {
+			out = append(out, node.Project)
+		}
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	visit(root)
+	return out
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsFold(want, have []string) bool {
+	for _, value := range have {
+		if containsFold(want, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestPrune(t *testing.T) {
+	web := &model.Node{Name: "web", Project: &model.Project{
+		Name:     "web",
+		Category: "Serious",
+		Metrics:  model.ProjectMetrics{Frameworks: []string{"React"}, ProjectType: "web"},
+	}}
+	tool := &model.Node{Name: "tool", Project: &model.Project{
+		Name:     "tool",
+		Category: "Experiment",
+		Metrics:  model.ProjectMetrics{Frameworks: []string{"Cobra"}, ProjectType: "cli"},
+	}}
+	notes := &model.Node{Name: "notes"}
+	root := &model.Node{
+		Name: "root",
+		Children: []*model.Node{
+			{Name: "apps", Children: []*model.Node{web}},
+			{Name: "tools", Children: []*model.Node{tool}},
+			notes,
+		},
+	}
+
+	pruned := Prune(root, Filter{Frameworks: []string{"react"}})
+	if len(pruned.Children) != 1 || pruned.Children[0].Name != "apps" {
+		t.Fatalf("expected only apps to remain, got %+v", pruned.Children)
+	}
+	if got := Projects(pruned, Filter{}); len(got) != 1 || got[0].Name != "web" {
+		t.Fatalf("unexpected projects after prune: %+v", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	project := &model.Project{
+		Category: "Archived",
+		Tags:     []string{"done", "oss"},
+		Owner:    "ana",
+		Status:   "maintained",
+		Metrics:  model.ProjectMetrics{ProjectType: "cli"},
+	}
+	if !(Filter{Categories: []string{"archived", "experiment"}}).Match(project) {
+		t.Fatalf("expected category match")
+	}
+	if (Filter{Categories: []string{"archived"}, Types: []string{"web"}}).Match(project) {
+		t.Fatalf("expected type mismatch to exclude project")
+	}
+	if !(Filter{Tags: []string{"OSS"}}).Match(project) || (Filter{Tags: []string{"client"}}).Match(project) {
+		t.Fatalf("expected tags to match case-insensitively")
+	}
+	if !(Filter{Owners: []string{"Ana"}, Statuses: []string{"maintained"}}).Match(project) || (Filter{Statuses: []string{"wip"}}).Match(project) {
+		t.Fatalf("expected owner and status filters to apply")
+	}
+}
@@ -3,25 +3,84 @@ package model
 import "time"
 
 type Project struct {
-	Path     string         `json:"path"`
-	Name     string         `json:"name"`
-	Metrics  ProjectMetrics `json:"metrics"`
-	Scores   ProjectScores  `json:"scores"`
-	Category string         `json:"category"`
+	Path        string   `json:"path"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Owner       string   `json:"owner,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	// PinnedCategory is set from the project's .proj-audit.yaml and takes
+	// precedence over the category rules.
+	PinnedCategory string         `json:"pinnedCategory,omitempty"`
+	Notes          []Note         `json:"notes,omitempty"`
+	Metrics        ProjectMetrics `json:"metrics"`
+	Scores         ProjectScores  `json:"scores"`
+	Category       string         `json:"category"`
+	Explanation    *Explanation   `json:"explanation,omitempty"`
 }
 
 type ProjectMetrics struct {
-	HasGit      bool      `json:"hasGit"`
-	CommitCount int       `json:"commitCount"`
-	ActiveDays  int       `json:"activeDays"`
-	Languages   []string  `json:"languages"`
-	Files       int       `json:"files"`
-	LinesOfCode int       `json:"linesOfCode"`
-	HasREADME   bool      `json:"hasReadme"`
-	HasTests    bool      `json:"hasTests"`
-	HasCI       bool      `json:"hasCi"`
-	HasDocker   bool      `json:"hasDocker"`
-	LastTouched time.Time `json:"lastTouched"`
+	HasGit             bool              `json:"hasGit"`
+	CommitCount        int               `json:"commitCount"`
+	ActiveDays         int               `json:"activeDays"`
+	Languages          []string          `json:"languages"`
+	Frameworks         []string          `json:"frameworks,omitempty"`
+	ProjectType        string            `json:"projectType,omitempty"`
+	Files              int               `json:"files"`
+	LinesOfCode        int               `json:"linesOfCode"`
+	SourceBytes        int64             `json:"sourceBytes"`
+	ArtifactBytes      int64             `json:"artifactBytes"`
+	Artifacts          []Artifact        `json:"artifacts,omitempty"`
+	HasREADME          bool              `json:"hasReadme"`
+	HasTests           bool              `json:"hasTests"`
+	TestFiles          int               `json:"testFiles"`
+	TestLinesOfCode    int               `json:"testLinesOfCode"`
+	TestRatio          float64           `json:"testRatio"`
+	HasCI              bool              `json:"hasCi"`
+	CISystems          []string          `json:"ciSystems,omitempty"`
+	HasDocker          bool              `json:"hasDocker"`
+	HasLicense         bool              `json:"hasLicense"`
+	License            string            `json:"license,omitempty"`
+	Manifests          []Manifest        `json:"manifests,omitempty"`
+	Dependencies       int               `json:"dependencies"`
+	DevDependencies    int               `json:"devDependencies"`
+	Toolchains         map[string]string `json:"toolchains,omitempty"`
+	PinnedDependencies int               `json:"pinnedDependencies"`
+	Stale              []StaleItem       `json:"stale,omitempty"`
+	IsStale            bool              `json:"isStale"`
+	LastTouched        time.Time         `json:"lastTouched"`
+	Remote             string            `json:"remote,omitempty"`
+}
+
+// Note is a dated free-text note from the project registry.
+type Note struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+type Manifest struct {
+	File            string `json:"file"`
+	Ecosystem       string `json:"ecosystem"`
+	Name            string `json:"name,omitempty"`
+	Description     string `json:"description,omitempty"`
+	Version         string `json:"version,omitempty"`
+	Toolchain       string `json:"toolchain,omitempty"`
+	Dependencies    int    `json:"dependencies"`
+	DevDependencies int    `json:"devDependencies"`
+}
+
+type Artifact struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+type StaleItem struct {
+	Kind      string `json:"kind"`
+	Ecosystem string `json:"ecosystem,omitempty"`
+	Name      string `json:"name"`
+	Pinned    string `json:"pinned"`
+	Current   string `json:"current"`
 }
 
 type ProjectScores struct {
@@ -29,11 +88,43 @@ type ProjectScores struct {
 	Polish  int `json:"polish"`
 	Recency int `json:"recency"`
 	Overall int `json:"overall"`
+	// Max is the highest Overall the scoring config can award; Normalized is
+	// Overall scaled to 0–100 against it. Percentile ranks Overall among the
+	// projects of one scan.
+	Max        int `json:"max"`
+	Normalized int `json:"normalized"`
+	Percentile int `json:"percentile"`
+}
+
+// Explanation records how a project's scores and category were derived.
+type Explanation struct {
+	Components []ScoreComponent `json:"components"`
+	Categories []CategoryCheck  `json:"categories"`
+}
+
+// ScoreComponent is one scoring rule and the points it contributed.
+type ScoreComponent struct {
+	Score  string `json:"score"`
+	Rule   string `json:"rule"`
+	Value  string `json:"value"`
+	Detail string `json:"detail"`
+	Points int    `json:"points"`
+}
+
+// CategoryCheck is the outcome of testing one category rule. Failed lists
+// the bounds that did not hold.
+type CategoryCheck struct {
+	Category string   `json:"category"`
+	Matched  bool     `json:"matched"`
+	Pinned   bool     `json:"pinned,omitempty"`
+	Failed   []string `json:"failed,omitempty"`
 }
 
 type Node struct {
-	Name     string   `json:"name"`
-	Path     string   `json:"path"`
-	Children []*Node  `json:"children"`
-	Project  *Project `json:"project,omitempty"`
+	Name          string   `json:"name"`
+	Path          string   `json:"path"`
+	Children      []*Node  `json:"children"`
+	Project       *Project `json:"project,omitempty"`
+	SourceBytes   int64    `json:"sourceBytes,omitempty"`
+	ArtifactBytes int64    `json:"artifactBytes,omitempty"`
 }
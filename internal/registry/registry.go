@@ -0,0 +1,213 @@
+// Package registry keeps tags, notes, an owner and a status for projects in
+// one central file, so projects can be annotated without committing anything
+// into them. Entries are keyed by a project's git remote URL, which survives
+// moves and fresh clones, or by its absolute path.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// FileName is the registry's name inside the user config directory.
+const FileName = "registry.json"
+
+// Entry is what the registry records about one project.
+type Entry struct {
+	Tags   []string     `json:"tags,omitempty"`
+	Owner  string       `json:"owner,omitempty"`
+	Status string       `json:"status,omitempty"`
+	Notes  []model.Note `json:"notes,omitempty"`
+}
+
+func (e *Entry) IsEmpty() bool {
+	return len(e.Tags) == 0 && e.Owner == "" && e.Status == "" && len(e.Notes) == 0
+}
+
+// Tag applies tag edits: "+name" or "name" adds a tag, "-name" removes it.
+// Tags compare case-insensitively.
+func (e *Entry) Tag(edits []string) error {
+	for _, edit := range edits {
+		name := strings.TrimLeft(edit, "+-")
+		if name == "" {
+			return fmt.Errorf("invalid tag edit %q", edit)
+		}
+		if strings.HasPrefix(edit, "-") {
+			e.Tags = removeFold(e.Tags, name)
+			continue
+		}
+		e.Tags = addFold(e.Tags, name)
+	}
+	return nil
+}
+
+// Registry maps project keys to entries.
+type Registry struct {
+	Projects map[string]*Entry `json:"projects"`
+	path     string
+}
+
+// DefaultPath is proj-audit/registry.json under the user config directory
+// ($XDG_CONFIG_HOME or ~/.config on Linux).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config dir: %w", err)
+	}
+	return filepath.Join(dir, "proj-audit", FileName), nil
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{Projects: make(map[string]*Entry), path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read registry: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parse registry %s: %w", path, err)
+	}
+	if r.Projects == nil {
+		r.Projects = make(map[string]*Entry)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to the file it was loaded from, dropping
+// empty entries. The file is replaced atomically.
+func (r *Registry) Save() error {
+	for key, entry := range r.Projects {
+		if entry == nil || entry.IsEmpty() {
+			delete(r.Projects, key)
+		}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode registry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("create registry dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".registry-*.json")
+	if err != nil {
+		return fmt.Errorf("write registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write registry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("write registry: %w", err)
+	}
+	return nil
+}
+
+// Entry returns the entry to edit for a project, creating one if needed. An
+// existing entry is reused, preferring the remote key; new entries are keyed
+// by the remote when the project has one.
+func (r *Registry) Entry(path, remote string) *Entry {
+	keys := keys(path, remote)
+	for _, key := range keys {
+		if entry, ok := r.Projects[key]; ok && entry != nil {
+			return entry
+		}
+	}
+	entry := &Entry{}
+	r.Projects[keys[0]] = entry
+	return entry
+}
+
+// Apply merges the entries for p's remote and path into p. Registry tags are
+// added to the project's own; owner and status come from the first entry
+// that sets them.
+func (r *Registry) Apply(p *model.Project) {
+	for _, key := range keys(p.Path, p.Metrics.Remote) {
+		entry, ok := r.Projects[key]
+		if !ok || entry == nil {
+			continue
+		}
+		for _, tag := range entry.Tags {
+			p.Tags = addFold(p.Tags, tag)
+		}
+		if p.Owner == "" {
+			p.Owner = entry.Owner
+		}
+		if p.Status == "" {
+			p.Status = entry.Status
+		}
+		p.Notes = append(p.Notes, entry.Notes...)
+	}
+	sort.SliceStable(p.Notes, func(i, j int) bool { return p.Notes[i].Time.Before(p.Notes[j].Time) })
+}
+
+// keys lists the registry keys for a project, remote first.
+func keys(path, remote string) []string {
+	var out []string
+	if remote = NormalizeRemote(remote); remote != "" {
+		out = append(out, remote)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return append(out, filepath.Clean(path))
+}
+
+// NormalizeRemote reduces the SSH and HTTPS forms of a remote URL to
+// host/owner/repo (with a lowercased host), so "git@github.com:me/app.git" and
+// "https://github.com/me/app" share an entry.
+func NormalizeRemote(url string) string {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return ""
+	}
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+		if at := strings.Index(url, "@"); at >= 0 && at < strings.Index(url+"/", "/") {
+			url = url[at+1:]
+		}
+	} else if at, colon := strings.Index(url, "@"), strings.Index(url, ":"); at >= 0 && colon > at {
+		url = url[at+1:colon] + "/" + url[colon+1:]
+	}
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+	host, rest, _ := strings.Cut(url, "/")
+	if rest == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(host) + "/" + rest
+}
+
+func addFold(items []string, value string) []string {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return items
+		}
+	}
+	return append(items, value)
+}
+
+func removeFold(items []string, value string) []string {
+	out := items[:0]
+	for _, item := range items {
+		if !strings.EqualFold(item, value) {
+			out = append(out, item)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestRegistryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proj-audit", FileName)
+	reg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load of a missing registry returned error: %v", err)
+	}
+
+	byRemote := reg.Entry("/src/app", "git@github.com:Me/app.git")
+	if err := byRemote.Tag([]string{"+keep", "wip", "oss"}); err != nil {
+		t.Fatalf("Tag returned error: %v", err)
+	}
+	if err := byRemote.Tag([]string{"-WIP"}); err != nil {
+		t.Fatalf("Tag returned error: %v", err)
+	}
+	byRemote.Owner = "ana"
+	reg.Entry("/src/scratch", "").Notes = []model.Note{{Time: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Text: "try again"}}
+	reg.Entry("/src/empty", "")
+	if err := reg.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(loaded.Projects) != 2 {
+		t.Fatalf("expected empty entries to be dropped, got %v", loaded.Projects)
+	}
+	entry, ok := loaded.Projects["github.com/Me/app"]
+	if !ok || !reflect.DeepEqual(entry.Tags, []string{"keep", "oss"}) || entry.Owner != "ana" {
+		t.Fatalf("unexpected remote entry: %+v", loaded.Projects)
+	}
+
+	// A clone at another path shares the remote's entry.
+	clone := &model.Project{Path: "/elsewhere/app", Tags: []string{"oss"}, Metrics: model.ProjectMetrics{Remote: "https://github.com/Me/app"}}
+	loaded.Apply(clone)
+	if !reflect.DeepEqual(clone.Tags, []string{"oss", "keep"}) || clone.Owner != "ana" {
+		t.Fatalf("unexpected project after Apply: %+v", clone)
+	}
+	scratch := &model.Project{Path: "/src/scratch"}
+	loaded.Apply(scratch)
+	if len(scratch.Notes) != 1 || scratch.Notes[0].Text != "try again" {
+		t.Fatalf("expected the path entry's note, got %+v", scratch.Notes)
+	}
+}
+
+func TestNormalizeRemote(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:me/app.git":          "github.com/me/app",
+		"https://GitHub.com/me/app":          "github.com/me/app",
+		"https://user@gitlab.com/me/app.git": "gitlab.com/me/app",
+		"ssh://git@host:2222/me/app.git/":    "host:2222/me/app",
+		"/srv/git/app.git":                   "/srv/git/app",
+		"":                                   "",
+	}
+	for input, want := range tests {
+		if got := NormalizeRemote(input); got != want {
+			t.Errorf("NormalizeRemote(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
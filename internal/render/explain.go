@@ -0,0 +1,61 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// WriteExplanation prints a project's score breakdown and category checks.
+func WriteExplanation(w io.Writer, p *model.Project) error {
+	if p == nil || p.Explanation == nil {
+		return fmt.Errorf("no explanation available")
+	}
+	fmt.Fprintf(w, "%s (%s)\n", p.Name, p.Path)
+	fmt.Fprintf(w, "Category: %s | Score: %d/100 | Overall: %d of %d points\n", p.Category, p.Scores.Normalized, p.Scores.Overall, p.Scores.Max)
+	if p.Priority != 0 || len(p.Tags) > 0 {
+		fmt.Fprintf(w, "Priority: %d | Tags: %s\n", p.Priority, strings.Join(p.Tags, ", "))
+	}
+	if p.Owner != "" || p.Status != "" {
+		fmt.Fprintf(w, "Owner: %s | Status: %s\n", p.Owner, p.Status)
+	}
+	for _, note := range p.Notes {
+		fmt.Fprintf(w, "Note %s: %s\n", note.Time.Format("2006-01-02"), note.Text)
+	}
+
+	totals := map[string]int{"effort": p.Scores.Effort, "polish": p.Scores.Polish, "recency": p.Scores.Recency}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	section := ""
+	for _, c := range p.Explanation.Components {
+		if c.Score != section {
+			section = c.Score
+			fmt.Fprintf(tw, "\n%s: %d\n", strings.ToUpper(section[:1])+section[1:], totals[section])
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%+d\n", c.Rule, c.Value, c.Detail, c.Points)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nCategories (first match wins):")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, check := range p.Explanation.Categories {
+		result := "no"
+		if check.Matched {
+			result = "yes"
+		}
+		if check.Pinned {
+			fmt.Fprintf(tw, "  %s\t%s\tpinned by the project's .proj-audit.yaml\n", check.Category, result)
+			continue
+		}
+		if len(check.Failed) == 0 {
+			fmt.Fprintf(tw, "  %s\t%s\n", check.Category, result)
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", check.Category, result, strings.Join(check.Failed, "; "))
+	}
+	return tw.Flush()
+}
@@ -1,6 +1,12 @@
 package render
 
-import "github.com/ErikOlson/proj-audit/internal/model"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
 
 func flattenProjects(root *model.Node) []*model.Project {
 	var projects []*model.Project
@@ -15,7 +21,79 @@ func collectProjects(node *model.Node, projects *[]*model.Project) {
 	if node.Project != nil {
 		*projects = append(*projects, node.Project)
 	}
-	for _, child := range node.Children {
+	for _, child := range byPriority(node.Children) {
 		collectProjects(child, projects)
 	}
 }
+
+// byPriority orders sibling nodes with higher-priority projects first,
+// keeping the scan order otherwise.
+func byPriority(nodes []*model.Node) []*model.Node {
+	priority := func(node *model.Node) int {
+		if node.Project == nil {
+			return 0
+		}
+		return node.Project.Priority
+	}
+	sorted := append([]*model.Node(nil), nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return priority(sorted[i]) > priority(sorted[j]) })
+	return sorted
+}
+
+// markdownCell escapes text for use inside a markdown table cell.
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// projectUsage is the disk usage attributed to a single project, excluding
+// any nested projects (which report their own usage).
+type projectUsage struct {
+	project       *model.Project
+	sourceBytes   int64
+	artifactBytes int64
+}
+
+func collectUsage(root *model.Node) []projectUsage {
+	var out []projectUsage
+	var visit func(node *model.Node) (int64, int64)
+	// visit returns the usage of the nearest projects at or below node.
+	visit = func(node *model.Node) (int64, int64) {
+		if node == nil {
+			return 0, 0
+		}
+		var nestedSource, nestedArtifact int64
+		for _, child := range node.Children {
+			s, a := visit(child)
+			nestedSource += s
+			nestedArtifact += a
+		}
+		if node.Project == nil {
+			return nestedSource, nestedArtifact
+		}
+		m := node.Project.Metrics
+		out = append(out, projectUsage{
+			project:       node.Project,
+			sourceBytes:   max(m.SourceBytes-nestedSource, 0),
+			artifactBytes: max(m.ArtifactBytes-nestedArtifact, 0),
+		})
+		return m.SourceBytes, m.ArtifactBytes
+	}
+	visit(root)
+	return out
+}
+
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	suffixes := []string{"KB", "MB", "GB", "TB", "PB"}
+	i := -1
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
@@ -3,6 +3,7 @@ package render
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/ErikOlson/proj-audit/internal/model"
@@ -30,6 +31,18 @@ func (r *MarkdownRenderer) Render(root *model.Node, w io.Writer) error {
 		return err
 	}
 
+	if err := r.renderNotes(root, w); err != nil {
+		return err
+	}
+
+	if err := r.renderLicenses(root, w); err != nil {
+		return err
+	}
+
+	if err := r.renderDiskUsage(root, w); err != nil {
+		return err
+	}
+
 	treeBuf := &strings.Builder{}
 	if err := NewTreeRenderer().Render(root, treeBuf); err != nil {
 		return err
@@ -54,10 +67,10 @@ func (r *MarkdownRenderer) Render(root *model.Node, w io.Writer) error {
 func (r *MarkdownRenderer) renderTable(root *model.Node, w io.Writer) error {
 	projects := flattenProjects(root)
 
-	if _, err := fmt.Fprintln(w, "| Name | Path | Category | Score | Languages | Commits | Last Touched |"); err != nil {
+	if _, err := fmt.Fprintln(w, "| Name | Description | Path | Category | Score | Points | Percentile | Priority | Tags | Owner | Status | Languages | Commits | Last Touched |"); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprintln(w, "|------|------|----------|-------|-----------|---------|--------------|"); err != nil {
+	if _, err := fmt.Fprintln(w, "|------|-------------|------|----------|-------|--------|------------|----------|------|-------|--------|-----------|---------|--------------|"); err != nil {
 		return err
 	}
 
@@ -66,7 +79,7 @@ func (r *MarkdownRenderer) renderTable(root *model.Node, w io.Writer) error {
 		if category == "" {
 			category = "Uncategorized"
 		}
-		langs := strings.Join(project.Metrics.Languages, ", ")
+		langs := markdownCell(strings.Join(project.Metrics.Languages, ", "))
 		if langs == "" {
 			langs = "-"
 		}
@@ -76,11 +89,44 @@ func (r *MarkdownRenderer) renderTable(root *model.Node, w io.Writer) error {
 			last = project.Metrics.LastTouched.Format("2006-01-02")
 		}
 
-		row := fmt.Sprintf("| %s | %s | %s | %d | %s | %d | %s |",
-			project.Name,
-			project.Path,
+		description := markdownCell(project.Description)
+		if description == "" {
+			description = "-"
+		}
+		if project.PinnedCategory != "" {
+			category += " (pinned)"
+		}
+		category = markdownCell(category)
+		priority := "-"
+		if project.Priority != 0 {
+			priority = fmt.Sprint(project.Priority)
+		}
+		tags := markdownCell(strings.Join(project.Tags, ", "))
+		if tags == "" {
+			tags = "-"
+		}
+		owner := markdownCell(project.Owner)
+		if owner == "" {
+			owner = "-"
+		}
+		status := markdownCell(project.Status)
+		if status == "" {
+			status = "-"
+		}
+
+		row := fmt.Sprintf("| %s | %s | %s | %s | %d | %d/%d | %d | %s | %s | %s | %s | %s | %d | %s |",
+			markdownCell(project.Name),
+			description,
+			markdownCell(project.Path),
 			category,
+			project.Scores.Normalized,
 			project.Scores.Overall,
+			project.Scores.Max,
+			project.Scores.Percentile,
+			priority,
+			tags,
+			owner,
+			status,
 			langs,
 			commits,
 			last,
@@ -91,10 +137,164 @@ func (r *MarkdownRenderer) renderTable(root *model.Node, w io.Writer) error {
 	}
 
 	if len(projects) == 0 {
-		if _, err := fmt.Fprintln(w, "| _no projects detected_ | - | - | - | - | - | - |"); err != nil {
+		if _, err := fmt.Fprintln(w, "| _no projects detected_ | - | - | - | - | - | - | - | - | - | - | - | - | - |"); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+func (r *MarkdownRenderer) renderNotes(root *model.Node, w io.Writer) error {
+	var noted []*model.Project
+	for _, project := range flattenProjects(root) {
+		if len(project.Notes) > 0 {
+			noted = append(noted, project)
+		}
+	}
+	if len(noted) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "## Notes"); err != nil {
+		return err
+	}
+	for _, project := range noted {
+		if _, err := fmt.Fprintf(w, "\n**%s**\n\n", project.Name); err != nil {
+			return err
+		}
+		for _, note := range project.Notes {
+			if _, err := fmt.Fprintf(w, "- %s: %s\n", note.Time.Format("2006-01-02"), note.Text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *MarkdownRenderer) renderLicenses(root *model.Node, w io.Writer) error {
+	projects := flattenProjects(root)
+	if len(projects) == 0 {
+		return nil
+	}
+
+	byLicense := make(map[string][]string)
+	for _, project := range projects {
+		license := project.Metrics.License
+		if license == "" {
+			license = "None"
+		}
+		byLicense[license] = append(byLicense[license], project.Name)
+	}
+
+	licenses := make([]string, 0, len(byLicense))
+	for license := range byLicense {
+		licenses = append(licenses, license)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		a, b := byLicense[licenses[i]], byLicense[licenses[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return licenses[i] < licenses[j]
+	})
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "## Licenses"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "| License | Count | Projects |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|---------|-------|----------|"); err != nil {
+		return err
+	}
+	for _, license := range licenses {
+		names := byLicense[license]
+		row := fmt.Sprintf("| %s | %d | %s |", markdownCell(license), len(names), markdownCell(strings.Join(names, ", ")))
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MarkdownRenderer) renderDiskUsage(root *model.Node, w io.Writer) error {
+	usage := collectUsage(root)
+	if len(usage) == 0 {
+		return nil
+	}
+
+	type categoryUsage struct {
+		projects      int
+		sourceBytes   int64
+		artifactBytes int64
+	}
+	byCategory := make(map[string]*categoryUsage)
+	var totalArtifacts int64
+	for _, u := range usage {
+		category := u.project.Category
+		if category == "" {
+			category = "Uncategorized"
+		}
+		c, ok := byCategory[category]
+		if !ok {
+			c = &categoryUsage{}
+			byCategory[category] = c
+		}
+		c.projects++
+		c.sourceBytes += u.sourceBytes
+		c.artifactBytes += u.artifactBytes
+		totalArtifacts += u.artifactBytes
+	}
+
+	categories := make([]string, 0, len(byCategory))
+	for category := range byCategory {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := byCategory[categories[i]], byCategory[categories[j]]
+		if a.artifactBytes != b.artifactBytes {
+			return a.artifactBytes > b.artifactBytes
+		}
+		return categories[i] < categories[j]
+	})
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "## Disk Usage"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s reclaimable from build artifacts across %d projects.\n", FormatBytes(totalArtifacts), len(usage)); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "| Category | Projects | Source | Reclaimable |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|----------|----------|--------|-------------|"); err != nil {
+		return err
+	}
+	for _, category := range categories {
+		c := byCategory[category]
+		row := fmt.Sprintf("| %s | %d | %s | %s |", markdownCell(category), c.projects, FormatBytes(c.sourceBytes), FormatBytes(c.artifactBytes))
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+func TestMarkdownRendererEscapesTableCells(t *testing.T) {
+	project := &model.Project{
+		Name:           "tools|misc\nold",
+		Path:           "/src/tools|misc",
+		Category:       "Big|Small",
+		PinnedCategory: "Big|Small",
+		Metrics:        model.ProjectMetrics{License: "MIT|Apache", SourceBytes: 10, ArtifactBytes: 20},
+	}
+	root := &model.Node{Name: "src", Path: "/src", Children: []*model.Node{{Name: project.Name, Path: project.Path, Project: project}}}
+
+	var b strings.Builder
+	if err := NewMarkdownRenderer().Render(root, &b); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	report, _, _ := strings.Cut(b.String(), "```")
+
+	// Every row of a table must have as many cells as its header.
+	header := 0
+	for _, line := range strings.Split(report, "\n") {
+		if !strings.HasPrefix(line, "|") {
+			header = 0
+			continue
+		}
+		cells := strings.Count(line, "|") - strings.Count(line, `\|`)
+		if header == 0 {
+			header = cells
+		} else if cells != header {
+			t.Fatalf("row has %d separators, header has %d:\n%s", cells, header, line)
+		}
+	}
+	for _, want := range []string{`tools\|misc old`, `/src/tools\|misc`, `Big\|Small (pinned)`, `| Big\|Small | 1 |`, `MIT\|Apache`} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("expected %q in the report:\n%s", want, report)
+		}
+	}
+}
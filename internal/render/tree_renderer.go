@@ -8,7 +8,26 @@ import (
 	"github.com/ErikOlson/proj-audit/internal/model"
 )
 
-type TreeRenderer struct{}
+type TreeRenderer struct {
+	// ShowDescriptions appends each project's description after its summary.
+	ShowDescriptions bool
+	// Colors maps category names to color names; when set, project summaries
+	// are wrapped in the matching ANSI escape codes.
+	Colors map[string]string
+}
+
+var ansiColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"grey":    "90",
+}
 
 func NewTreeRenderer() *TreeRenderer {
 	return &TreeRenderer{}
@@ -19,11 +38,11 @@ func (r *TreeRenderer) Render(root *model.Node, w io.Writer) error {
 		return nil
 	}
 
-	if _, err := fmt.Fprintln(w, formatNodeLine(root.Name, root.Project)); err != nil {
+	if _, err := fmt.Fprintln(w, r.formatNodeLine(root.Name, root.Project)); err != nil {
 		return err
 	}
 
-	for i, child := range root.Children {
+	for i, child := range byPriority(root.Children) {
 		if err := r.renderNode(child, "", i == len(root.Children)-1, w); err != nil {
 			return err
 		}
@@ -39,12 +58,12 @@ func (r *TreeRenderer) renderNode(node *model.Node, prefix string, isLast bool,
 		childPrefix = prefix + "    "
 	}
 
-	line := formatNodeLine(fmt.Sprintf("%s%s%s", prefix, connector, node.Name), node.Project)
+	line := r.formatNodeLine(fmt.Sprintf("%s%s%s", prefix, connector, node.Name), node.Project)
 	if _, err := fmt.Fprintln(w, line); err != nil {
 		return err
 	}
 
-	for i, child := range node.Children {
+	for i, child := range byPriority(node.Children) {
 		if err := r.renderNode(child, childPrefix, i == len(node.Children)-1, w); err != nil {
 			return err
 		}
@@ -52,11 +71,19 @@ func (r *TreeRenderer) renderNode(node *model.Node, prefix string, isLast bool,
 	return nil
 }
 
-func formatNodeLine(base string, project *model.Project) string {
+func (r *TreeRenderer) formatNodeLine(base string, project *model.Project) string {
 	if project == nil {
 		return base
 	}
-	return base + " " + formatProjectSummary(project)
+	summary := formatProjectSummary(project)
+	if code, ok := ansiColors[strings.ToLower(r.Colors[project.Category])]; ok {
+		summary = "\x1b[" + code + "m" + summary + "\x1b[0m"
+	}
+	line := base + " " + summary
+	if r.ShowDescriptions && project.Description != "" {
+		line += " — " + project.Description
+	}
+	return line
 }
 
 func formatProjectSummary(project *model.Project) string {
@@ -68,9 +95,12 @@ func formatProjectSummary(project *model.Project) string {
 	if category == "" {
 		category = "Uncategorized"
 	}
-	overall := project.Scores.Overall
+	if project.PinnedCategory != "" {
+		category += " (pinned)"
+	}
+	scores := project.Scores
 
-	parts := []string{fmt.Sprintf("%s: %d", category, overall)}
+	parts := []string{fmt.Sprintf("%s: %d (%d pts, p%d)", category, scores.Normalized, scores.Overall, scores.Percentile)}
 	if languages := strings.Join(project.Metrics.Languages, ","); languages != "" {
 		parts = append(parts, languages)
 	}
@@ -85,5 +115,18 @@ func formatProjectSummary(project *model.Project) string {
 		parts = append(parts, fmt.Sprintf("last: %s", project.Metrics.LastTouched.Format("2006-01")))
 	}
 
+	if project.Priority != 0 {
+		parts = append(parts, fmt.Sprintf("priority %d", project.Priority))
+	}
+	if len(project.Tags) > 0 {
+		parts = append(parts, "#"+strings.Join(project.Tags, " #"))
+	}
+	if project.Owner != "" {
+		parts = append(parts, "@"+project.Owner)
+	}
+	if project.Status != "" {
+		parts = append(parts, "status: "+project.Status)
+	}
+
 	return "[" + strings.Join(parts, " | ") + "]"
 }
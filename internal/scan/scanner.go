@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/ErikOlson/proj-audit/internal/config"
 	"github.com/ErikOlson/proj-audit/internal/model"
 )
 
@@ -20,6 +21,9 @@ type Scanner interface {
 type DefaultScanner struct {
 	ignoreDirs    map[string]struct{}
 	includeHidden bool
+	// Warnings lists the problems of the last Scan that did not stop it,
+	// such as a malformed .proj-audit.yaml.
+	Warnings []string
 }
 
 func NewDefaultScanner(ignoreDirs []string, includeHidden bool) *DefaultScanner {
@@ -50,6 +54,7 @@ func (s *DefaultScanner) Scan(root string, maxDepth int) (*model.Node, error) {
 		return nil, fmt.Errorf("root is not directory: %s", absRoot)
 	}
 
+	s.Warnings = nil
 	return s.scanDir(absRoot, 0, maxDepth)
 }
 
@@ -69,6 +74,19 @@ func (s *DefaultScanner) scanDir(path string, depth, maxDepth int) (*model.Node,
 			Name: node.Name,
 			Path: path,
 		}
+		// A broken override file costs that project its overrides, not the
+		// whole scan.
+		excluded, err := ApplyProjectFile(node.Project)
+		if err != nil {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("%s: ignoring overrides: %v", path, err))
+		}
+		if excluded {
+			// The root is kept so a scan always returns a tree.
+			if depth > 0 {
+				return nil, nil
+			}
+			node.Project = nil
+		}
 	}
 
 	if maxDepth > 0 && depth >= maxDepth {
@@ -88,6 +106,9 @@ func (s *DefaultScanner) scanDir(path string, depth, maxDepth int) (*model.Node,
 		if err != nil {
 			return nil, err
 		}
+		if child == nil {
+			continue
+		}
 		node.Children = append(node.Children, child)
 	}
 
@@ -109,6 +130,23 @@ func (s *DefaultScanner) shouldIgnore(name string) bool {
 	return ok
 }
 
+// ApplyProjectFile copies the overrides from the project's .proj-audit.yaml,
+// if any, onto p. It reports whether the file excludes the project.
+func ApplyProjectFile(p *model.Project) (bool, error) {
+	file, err := config.LoadProjectFile(p.Path)
+	if err != nil || file == nil {
+		return false, err
+	}
+	if file.Exclude {
+		return true, nil
+	}
+	p.Description = file.Description
+	p.Tags = file.Tags
+	p.Priority = file.Priority
+	p.PinnedCategory = file.Category
+	return false, nil
+}
+
 func isProjectDir(path string, entries []fs.DirEntry) bool {
 	markers := map[string]struct{}{
 		"go.mod":           {},
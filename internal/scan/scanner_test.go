@@ -3,6 +3,7 @@ package scan
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ErikOlson/proj-audit/internal/model"
@@ -58,3 +59,50 @@ func findNodeByName(node *model.Node, name string) *model.Node {
 	}
 	return nil
 }
+
+func TestDefaultScannerAppliesProjectFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/go.mod":               "module example.com/lib",
+		"lib/.proj-audit.yaml":     "category: Showcase\ndescription: Finished parser library\npriority: 2\ntags:\n  - done\n  - oss\n",
+		"scratch/package.json":     "{}",
+		"scratch/.proj-audit.yml":  "exclude: true\n",
+		"scratch/inner/Cargo.toml": "[package]\nname = \"inner\"\n",
+		"broken/go.mod":            "module example.com/broken",
+		"broken/.proj-audit.yaml":  "tags: [unclosed\n",
+	}
+	for name, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	scanner := NewDefaultScanner(nil, false)
+	tree, err := scanner.Scan(root, 0)
+	if err != nil {
+		t.Fatalf("Scan returned error: %v", err)
+	}
+	broken := findNodeByName(tree, "broken")
+	if broken == nil || broken.Project == nil || len(broken.Project.Tags) != 0 {
+		t.Fatalf("expected the broken project to be kept without overrides")
+	}
+	if len(scanner.Warnings) != 1 || !strings.Contains(scanner.Warnings[0], "broken") {
+		t.Fatalf("expected one warning for the broken project file, got %v", scanner.Warnings)
+	}
+
+	lib := findNodeByName(tree, "lib")
+	if lib == nil || lib.Project == nil {
+		t.Fatalf("expected lib project")
+	}
+	p := lib.Project
+	if p.PinnedCategory != "Showcase" || p.Description != "Finished parser library" || p.Priority != 2 || len(p.Tags) != 2 {
+		t.Fatalf("expected overrides to be applied, got %+v", p)
+	}
+	if findNodeByName(tree, "scratch") != nil || findNodeByName(tree, "inner") != nil {
+		t.Fatalf("expected excluded project and its subtree to be dropped")
+	}
+}
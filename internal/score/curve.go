@@ -0,0 +1,79 @@
+package score
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ErikOlson/proj-audit/internal/config"
+)
+
+// defaultSteepness is the logistic slope used when none is configured; the
+// score falls from ~88% to ~12% of its points over about 80 days.
+const defaultSteepness = 0.05
+
+// checkCurve reports a curve whose type is not one of allowed or whose
+// parameters cannot produce a score.
+func checkCurve(c *config.Curve, allowed ...string) error {
+	known := false
+	for _, t := range allowed {
+		if c.Type == t {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("curve type must be one of %v, got %q", allowed, c.Type)
+	}
+	var param string
+	var value float64
+	switch c.Type {
+	case "linear":
+		param, value = "maxDays", c.MaxDays
+	case "exponential":
+		param, value = "halfLife", c.HalfLife
+	case "logistic":
+		param, value = "midpoint", c.Midpoint
+	case "logarithmic":
+		param, value = "saturation", c.Saturation
+	}
+	if value <= 0 {
+		return fmt.Errorf("%s curve needs a positive %s", c.Type, param)
+	}
+	return nil
+}
+
+// evalCurve returns the points for x (days of age, or a commit count),
+// rounded to the nearest integer.
+func evalCurve(c *config.Curve, x float64) int {
+	x = math.Max(x, 0)
+	var fraction float64
+	switch c.Type {
+	case "linear":
+		fraction = 1 - x/c.MaxDays
+	case "exponential":
+		fraction = math.Pow(0.5, x/c.HalfLife)
+	case "logistic":
+		k := c.Steepness
+		if k <= 0 {
+			k = defaultSteepness
+		}
+		fraction = 1 / (1 + math.Exp(k*(x-c.Midpoint)))
+	case "logarithmic":
+		fraction = math.Log1p(x) / math.Log1p(c.Saturation)
+	}
+	fraction = math.Min(math.Max(fraction, 0), 1)
+	return int(math.Round(c.Points * fraction))
+}
+
+func describeCurve(c *config.Curve) string {
+	switch c.Type {
+	case "linear":
+		return fmt.Sprintf("linear to 0 at %g days", c.MaxDays)
+	case "exponential":
+		return fmt.Sprintf("exponential, half-life %g days", c.HalfLife)
+	case "logistic":
+		return fmt.Sprintf("logistic, midpoint %g days", c.Midpoint)
+	case "logarithmic":
+		return fmt.Sprintf("logarithmic, full at %g", c.Saturation)
+	}
+	return c.Type
+}
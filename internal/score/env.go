@@ -0,0 +1,100 @@
+package score
+
+import (
+	"time"
+
+	"github.com/ErikOlson/proj-audit/internal/expr"
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// metricVars are the variables available to custom score components.
+var metricVars = map[string]expr.Type{
+	"commits":            expr.Number,
+	"activeDays":         expr.Number,
+	"ageDays":            expr.Number,
+	"files":              expr.Number,
+	"loc":                expr.Number,
+	"testFiles":          expr.Number,
+	"testLoc":            expr.Number,
+	"testRatio":          expr.Number,
+	"dependencies":       expr.Number,
+	"devDependencies":    expr.Number,
+	"pinnedDependencies": expr.Number,
+	"sourceBytes":        expr.Number,
+	"artifactBytes":      expr.Number,
+	"hasGit":             expr.Bool,
+	"hasReadme":          expr.Bool,
+	"hasTests":           expr.Bool,
+	"hasCI":              expr.Bool,
+	"hasDocker":          expr.Bool,
+	"hasLicense":         expr.Bool,
+	"isStale":            expr.Bool,
+	"license":            expr.String,
+	"projectType":        expr.String,
+	"languages":          expr.List,
+	"frameworks":         expr.List,
+	"ciSystems":          expr.List,
+}
+
+// categoryVars adds the computed scores, which category rules can also use.
+var categoryVars = withScoreVars(metricVars)
+
+func withScoreVars(vars map[string]expr.Type) map[string]expr.Type {
+	out := make(map[string]expr.Type, len(vars)+4)
+	for name, t := range vars {
+		out[name] = t
+	}
+	for _, name := range []string{"effort", "polish", "recency", "overall", "normalized"} {
+		out[name] = expr.Number
+	}
+	return out
+}
+
+// ageDays is the number of days since the project was last touched, or -1
+// when that is unknown.
+func ageDays(now time.Time, m model.ProjectMetrics) int {
+	if m.LastTouched.IsZero() {
+		return -1
+	}
+	return int(now.Sub(m.LastTouched).Hours() / 24)
+}
+
+func metricsEnv(now time.Time, m model.ProjectMetrics) expr.Env {
+	return expr.Env{
+		"commits":            expr.NumberValue(float64(m.CommitCount)),
+		"activeDays":         expr.NumberValue(float64(m.ActiveDays)),
+		"ageDays":            expr.NumberValue(float64(ageDays(now, m))),
+		"files":              expr.NumberValue(float64(m.Files)),
+		"loc":                expr.NumberValue(float64(m.LinesOfCode)),
+		"testFiles":          expr.NumberValue(float64(m.TestFiles)),
+		"testLoc":            expr.NumberValue(float64(m.TestLinesOfCode)),
+		"testRatio":          expr.NumberValue(m.TestRatio),
+		"dependencies":       expr.NumberValue(float64(m.Dependencies)),
+		"devDependencies":    expr.NumberValue(float64(m.DevDependencies)),
+		"pinnedDependencies": expr.NumberValue(float64(m.PinnedDependencies)),
+		"sourceBytes":        expr.NumberValue(float64(m.SourceBytes)),
+		"artifactBytes":      expr.NumberValue(float64(m.ArtifactBytes)),
+		"hasGit":             expr.BoolValue(m.HasGit),
+		"hasReadme":          expr.BoolValue(m.HasREADME),
+		"hasTests":           expr.BoolValue(m.HasTests),
+		"hasCI":              expr.BoolValue(m.HasCI),
+		"hasDocker":          expr.BoolValue(m.HasDocker),
+		"hasLicense":         expr.BoolValue(m.HasLicense),
+		"isStale":            expr.BoolValue(m.IsStale),
+		"license":            expr.StringValue(m.License),
+		"projectType":        expr.StringValue(m.ProjectType),
+		"languages":          expr.ListValue(m.Languages),
+		"frameworks":         expr.ListValue(m.Frameworks),
+		"ciSystems":          expr.ListValue(m.CISystems),
+	}
+}
+
+func categoryEnv(now time.Time, scores model.ProjectScores, m model.ProjectMetrics) expr.Env {
+	env := metricsEnv(now, m)
+	env["effort"] = expr.NumberValue(float64(scores.Effort))
+	env["polish"] = expr.NumberValue(float64(scores.Polish))
+	env["recency"] = expr.NumberValue(float64(scores.Recency))
+	env["overall"] = expr.NumberValue(float64(scores.Overall))
+	env["normalized"] = expr.NumberValue(float64(scores.Normalized))
+	return env
+}
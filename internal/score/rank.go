@@ -0,0 +1,28 @@
+package score
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ErikOlson/proj-audit/internal/model"
+)
+
+// Rank sets each project's percentile: the share of projects scoring below
+// it, counting ties as half, so the middle of the pack sits near 50.
+func Rank(projects []*model.Project) {
+	if len(projects) == 0 {
+		return
+	}
+	overall := make([]int, len(projects))
+	for i, p := range projects {
+		overall[i] = p.Scores.Overall
+	}
+	sort.Ints(overall)
+
+	n := float64(len(overall))
+	for _, p := range projects {
+		below := sort.SearchInts(overall, p.Scores.Overall)
+		equal := sort.SearchInts(overall, p.Scores.Overall+1) - below
+		p.Scores.Percentile = int(math.Round(100 * (float64(below) + float64(equal)/2) / n))
+	}
+}
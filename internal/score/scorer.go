@@ -1,119 +1,357 @@
 package score
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	"github.com/ErikOlson/proj-audit/internal/config"
+	"github.com/ErikOlson/proj-audit/internal/expr"
 	"github.com/ErikOlson/proj-audit/internal/model"
 )
 
+// Scorer scores metrics and sorts projects into categories. Categorize and
+// Explain read a project's Scores and Metrics, and honor its PinnedCategory
+// over the category rules.
 type Scorer interface {
 	Score(m model.ProjectMetrics) model.ProjectScores
-	Categorize(scores model.ProjectScores, m model.ProjectMetrics) string
+	Categorize(p *model.Project) string
+	Explain(p *model.Project) model.Explanation
 }
 
 type DefaultScorer struct {
 	Now    func() time.Time
 	config *config.ScoringConfig
+	// custom and when hold the compiled expressions of custom components and
+	// category rules, aligned by index; nil entries failed to compile.
+	custom []*expr.Expr
+	when   []*expr.Expr
+	// curveErr is set when a configured curve is invalid; curves then score 0.
+	curveErr error
+	errs     []error
+	max      int
 }
 
 func NewDefaultScorer(cfg *config.ScoringConfig) *DefaultScorer {
 	if cfg == nil {
 		cfg = config.DefaultScoringConfig()
 	}
-	return &DefaultScorer{
+	s := &DefaultScorer{
 		Now:    time.Now,
 		config: cfg,
 	}
+	s.compile()
+	return s
+}
+
+func (s *DefaultScorer) compile() {
+	if curve := s.config.Effort.CommitCurve; curve != nil {
+		if err := checkCurve(curve, "logarithmic"); err != nil {
+			s.curveErr = &config.KeyError{Key: "effort.commitCurve", Err: fmt.Errorf("effort.commitCurve: %w", err)}
+			s.errs = append(s.errs, s.curveErr)
+		}
+	}
+	if curve := s.config.Recency.Curve; curve != nil {
+		if err := checkCurve(curve, "linear", "exponential", "logistic"); err != nil {
+			s.curveErr = &config.KeyError{Key: "recency.curve", Err: fmt.Errorf("recency.curve: %w", err)}
+			s.errs = append(s.errs, s.curveErr)
+		}
+	}
+
+	s.max = maxPoints(s.config)
+
+	s.custom = make([]*expr.Expr, len(s.config.Custom))
+	for i, c := range s.config.Custom {
+		switch c.Score {
+		case "effort", "polish", "recency":
+		default:
+			s.errs = append(s.errs, &config.KeyError{
+				Key: fmt.Sprintf("custom[%d].score", i),
+				Err: fmt.Errorf("custom component %q: score must be effort, polish or recency, got %q", c.Name, c.Score),
+			})
+			continue
+		}
+		e, err := expr.Compile(c.When, metricVars, expr.Bool)
+		if err != nil {
+			s.errs = append(s.errs, &config.KeyError{Key: fmt.Sprintf("custom[%d].when", i), Err: fmt.Errorf("custom component %q: %w", c.Name, err)})
+			continue
+		}
+		s.custom[i] = e
+	}
+
+	s.when = make([]*expr.Expr, len(s.config.Categories))
+	for i, rule := range s.config.Categories {
+		if rule.When == "" {
+			continue
+		}
+		e, err := expr.Compile(rule.When, categoryVars, expr.Bool)
+		if err != nil {
+			s.errs = append(s.errs, &config.KeyError{Key: fmt.Sprintf("categories[%d].when", i), Err: fmt.Errorf("category %q: %w", rule.Name, err)})
+			continue
+		}
+		s.when[i] = e
+	}
+}
+
+// Validate reports expressions in the scoring config that failed to parse or
+// type-check. Rules with invalid expressions never match.
+func (s *DefaultScorer) Validate() error {
+	return errors.Join(s.errs...)
 }
 
 func (s *DefaultScorer) Score(m model.ProjectMetrics) model.ProjectScores {
 	var scores model.ProjectScores
+	for _, c := range s.components(m) {
+		switch c.Score {
+		case "effort":
+			scores.Effort += c.Points
+		case "polish":
+			scores.Polish += c.Points
+		case "recency":
+			scores.Recency += c.Points
+		}
+	}
+	scores.Overall = scores.Effort + scores.Polish + scores.Recency
+	scores.Max = s.max
+	scores.Normalized = normalize(scores.Overall, s.max)
+	return scores
+}
 
-	if len(s.config.Effort.Commit) > 0 {
-		scores.Effort += pickRangePoints(m.CommitCount, s.config.Effort.Commit)
+func (s *DefaultScorer) Categorize(p *model.Project) string {
+	checks := s.projectChecks(p)
+	if len(checks) == 0 || !checks[len(checks)-1].Matched {
+		return ""
 	}
-	if len(s.config.Effort.Active) > 0 {
-		scores.Effort += pickRangePoints(m.ActiveDays, s.config.Effort.Active)
+	return checks[len(checks)-1].Category
+}
+
+// Explain lists every scoring rule that was evaluated and the category rules
+// tested, in order, up to the one that matched.
+func (s *DefaultScorer) Explain(p *model.Project) model.Explanation {
+	return model.Explanation{
+		Components: s.components(p.Metrics),
+		Categories: s.projectChecks(p),
 	}
+}
 
-	if m.HasREADME {
-		scores.Polish += s.config.Polish.Readme
+// components evaluates each configured scoring rule against m.
+func (s *DefaultScorer) components(m model.ProjectMetrics) []model.ScoreComponent {
+	var out []model.ScoreComponent
+
+	if curve := s.config.Effort.CommitCurve; curve != nil {
+		points := 0
+		if s.curveErr == nil {
+			points = evalCurve(curve, float64(m.CommitCount))
+		}
+		out = append(out, model.ScoreComponent{Score: "effort", Rule: "commit", Value: fmt.Sprintf("%d commits", m.CommitCount), Detail: describeCurve(curve), Points: points})
+	} else if len(s.config.Effort.Commit) > 0 {
+		points, detail := pickRangePoints(m.CommitCount, s.config.Effort.Commit)
+		out = append(out, model.ScoreComponent{Score: "effort", Rule: "commit", Value: fmt.Sprintf("%d commits", m.CommitCount), Detail: detail, Points: points})
 	}
-	if m.HasTests {
-		scores.Polish += s.config.Polish.Tests
+	if len(s.config.Effort.Active) > 0 {
+		points, detail := pickRangePoints(m.ActiveDays, s.config.Effort.Active)
+		out = append(out, model.ScoreComponent{Score: "effort", Rule: "active", Value: fmt.Sprintf("%d active days", m.ActiveDays), Detail: detail, Points: points})
+	}
+
+	polishFlags := []struct {
+		rule    string
+		present bool
+		points  int
+	}{
+		{"readme", m.HasREADME, s.config.Polish.Readme},
+		{"tests", m.HasTests, s.config.Polish.Tests},
+		{"ci", m.HasCI, s.config.Polish.CI},
+		{"docker", m.HasDocker, s.config.Polish.Docker},
+		{"license", m.HasLicense, s.config.Polish.License},
 	}
-	if m.HasCI {
-		scores.Polish += s.config.Polish.CI
+	for _, flag := range polishFlags {
+		c := model.ScoreComponent{Score: "polish", Rule: flag.rule, Value: "missing", Detail: fmt.Sprintf("worth %d", flag.points)}
+		if flag.present {
+			c.Value = "present"
+			c.Points = flag.points
+		}
+		out = append(out, c)
 	}
-	if m.HasDocker {
-		scores.Polish += s.config.Polish.Docker
+	if len(s.config.Polish.TestRatio) > 0 {
+		points, detail := pickRatioPoints(m.TestRatio, s.config.Polish.TestRatio)
+		out = append(out, model.ScoreComponent{Score: "polish", Rule: "testRatio", Value: fmt.Sprintf("%.2f", m.TestRatio), Detail: detail, Points: points})
 	}
 
-	if !m.LastTouched.IsZero() && len(s.config.Recency) > 0 {
-		ageDays := int(s.Now().Sub(m.LastTouched).Hours() / 24)
-		scores.Recency += pickRecencyPoints(ageDays, s.config.Recency)
+	now := s.Now()
+	recency := s.config.Recency
+	if recency.Curve != nil || len(recency.Thresholds) > 0 {
+		c := model.ScoreComponent{Score: "recency", Rule: "recency", Value: "never touched", Detail: "no activity recorded"}
+		if age := ageDays(now, m); age >= 0 {
+			c.Value = fmt.Sprintf("%d days ago", age)
+			if recency.Curve != nil {
+				c.Detail = describeCurve(recency.Curve)
+				if s.curveErr == nil {
+					c.Points = evalCurve(recency.Curve, float64(age))
+				}
+			} else {
+				c.Points, c.Detail = pickRecencyPoints(age, recency.Thresholds)
+			}
+		}
+		out = append(out, c)
 	}
 
-	scores.Overall = scores.Effort + scores.Polish + scores.Recency
-	return scores
+	if len(s.config.Custom) > 0 {
+		env := metricsEnv(now, m)
+		for i, custom := range s.config.Custom {
+			c := model.ScoreComponent{Score: custom.Score, Rule: custom.Name, Value: "not matched", Detail: custom.When}
+			if e := s.custom[i]; e != nil {
+				if ok, err := e.EvalBool(env); err != nil {
+					c.Value = "error: " + err.Error()
+				} else if ok {
+					c.Value = "matched"
+					c.Points = custom.Points
+				}
+			} else {
+				c.Value = "invalid expression"
+			}
+			out = append(out, c)
+		}
+	}
+	return out
 }
 
-func (s *DefaultScorer) Categorize(scores model.ProjectScores, m model.ProjectMetrics) string {
-	cfg := s.config.Categories
-	switch {
-	case matchesRule(cfg.Experiment, scores, m):
-		return "Experiment"
-	case matchesRule(cfg.Prototype, scores, m):
-		return "Prototype"
-	case matchesRule(cfg.Archived, scores, m):
-		return "Archived"
-	case matchesRule(cfg.Product, scores, m):
-		return "Product-ish"
-	default:
-		return "Serious"
+// projectChecks is categoryChecks for p, except that a category pinned in
+// the project's .proj-audit.yaml bypasses the rules.
+func (s *DefaultScorer) projectChecks(p *model.Project) []model.CategoryCheck {
+	if p.PinnedCategory != "" {
+		return []model.CategoryCheck{{Category: p.PinnedCategory, Matched: true, Pinned: true}}
 	}
+	return s.categoryChecks(p.Scores, p.Metrics)
 }
 
-func pickRangePoints(value int, thresholds []config.RangeThreshold) int {
-	for _, th := range thresholds {
-		if value >= th.Min {
-			return th.Points
+// categoryChecks tests the category rules in order and stops at the first
+// match. If no rule matches, every check is returned unmatched.
+func (s *DefaultScorer) categoryChecks(scores model.ProjectScores, m model.ProjectMetrics) []model.CategoryCheck {
+	var checks []model.CategoryCheck
+	var env expr.Env
+	for i, rule := range s.config.Categories {
+		failed := failedBounds(rule, scores, m)
+		if rule.When != "" {
+			if env == nil {
+				env = categoryEnv(s.Now(), scores, m)
+			}
+			if !s.whenHolds(i, env) {
+				failed = append(failed, "when "+rule.When)
+			}
+		}
+		checks = append(checks, model.CategoryCheck{Category: rule.Name, Matched: len(failed) == 0, Failed: failed})
+		if len(failed) == 0 {
+			break
 		}
 	}
-	return 0
+	return checks
 }
 
-func pickRecencyPoints(ageDays int, thresholds []config.AgeThreshold) int {
-	for _, th := range thresholds {
-		if ageDays <= th.MaxDays {
-			return th.Points
-		}
+func (s *DefaultScorer) whenHolds(i int, env expr.Env) bool {
+	if s.when[i] == nil {
+		return false
 	}
-	return 0
+	ok, err := s.when[i].EvalBool(env)
+	return err == nil && ok
 }
 
-func matchesRule(rule config.CategoryRule, scores model.ProjectScores, m model.ProjectMetrics) bool {
-	if rule.CommitMax != nil && m.CommitCount > *rule.CommitMax {
-		return false
+// maxPoints is the highest overall score the config can award.
+func maxPoints(cfg *config.ScoringConfig) int {
+	total := 0
+	if cfg.Effort.CommitCurve != nil {
+		total += max(int(math.Round(cfg.Effort.CommitCurve.Points)), 0)
+	} else {
+		total += maxRangePoints(cfg.Effort.Commit)
 	}
-	if rule.EffortMax != nil && scores.Effort > *rule.EffortMax {
-		return false
+	total += maxRangePoints(cfg.Effort.Active)
+
+	for _, points := range []int{cfg.Polish.Readme, cfg.Polish.Tests, cfg.Polish.CI, cfg.Polish.Docker, cfg.Polish.License} {
+		total += max(points, 0)
 	}
-	if rule.EffortMin != nil && scores.Effort < *rule.EffortMin {
-		return false
+	best := 0
+	for _, th := range cfg.Polish.TestRatio {
+		best = max(best, th.Points)
 	}
-	if rule.PolishMax != nil && scores.Polish > *rule.PolishMax {
-		return false
+	total += best
+
+	if cfg.Recency.Curve != nil {
+		total += max(int(math.Round(cfg.Recency.Curve.Points)), 0)
+	} else {
+		best = 0
+		for _, th := range cfg.Recency.Thresholds {
+			best = max(best, th.Points)
+		}
+		total += best
 	}
-	if rule.PolishMin != nil && scores.Polish < *rule.PolishMin {
-		return false
+
+	for _, c := range cfg.Custom {
+		total += max(c.Points, 0)
 	}
-	if rule.RecencyMax != nil && scores.Recency > *rule.RecencyMax {
-		return false
+	return total
+}
+
+func maxRangePoints(thresholds []config.RangeThreshold) int {
+	best := 0
+	for _, th := range thresholds {
+		best = max(best, th.Points)
 	}
-	if rule.RecencyMin != nil && scores.Recency < *rule.RecencyMin {
-		return false
+	return best
+}
+
+// normalize scales overall to 0–100 against the maximum.
+func normalize(overall, maximum int) int {
+	if maximum <= 0 {
+		return 0
+	}
+	return min(max(int(math.Round(100*float64(overall)/float64(maximum))), 0), 100)
+}
+
+func pickRangePoints(value int, thresholds []config.RangeThreshold) (int, string) {
+	for _, th := range thresholds {
+		if value >= th.Min {
+			return th.Points, fmt.Sprintf(">= %d", th.Min)
+		}
+	}
+	return 0, "below every threshold"
+}
+
+func pickRatioPoints(value float64, thresholds []config.RatioThreshold) (int, string) {
+	for _, th := range thresholds {
+		if value >= th.Min {
+			return th.Points, fmt.Sprintf(">= %g", th.Min)
+		}
+	}
+	return 0, "below every threshold"
+}
+
+func pickRecencyPoints(ageDays int, thresholds []config.AgeThreshold) (int, string) {
+	for _, th := range thresholds {
+		if ageDays <= th.MaxDays {
+			return th.Points, fmt.Sprintf("within %d days", th.MaxDays)
+		}
+	}
+	return 0, "older than every threshold"
+}
+
+// failedBounds returns a description of every bound in rule that does not hold.
+func failedBounds(rule config.CategoryRule, scores model.ProjectScores, m model.ProjectMetrics) []string {
+	var failed []string
+	check := func(label, bound string, limit *int, value int, isMax bool) {
+		switch {
+		case limit == nil:
+		case isMax && value > *limit:
+			failed = append(failed, fmt.Sprintf("%s %d > %s %d", label, value, bound, *limit))
+		case !isMax && value < *limit:
+			failed = append(failed, fmt.Sprintf("%s %d < %s %d", label, value, bound, *limit))
+		}
 	}
-	return true
+	check("commits", "commitMax", rule.CommitMax, m.CommitCount, true)
+	check("effort", "effortMax", rule.EffortMax, scores.Effort, true)
+	check("effort", "effortMin", rule.EffortMin, scores.Effort, false)
+	check("polish", "polishMax", rule.PolishMax, scores.Polish, true)
+	check("polish", "polishMin", rule.PolishMin, scores.Polish, false)
+	check("recency", "recencyMax", rule.RecencyMax, scores.Recency, true)
+	check("recency", "recencyMin", rule.RecencyMin, scores.Recency, false)
+	return failed
 }
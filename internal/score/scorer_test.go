@@ -1,6 +1,8 @@
 package score
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -73,10 +75,241 @@ func TestDefaultScorerCategorize(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			scores := scorer.Score(tt.metrics)
-			got := scorer.Categorize(scores, tt.metrics)
+			got := scorer.Categorize(&model.Project{Scores: scores, Metrics: tt.metrics})
 			if got != tt.category {
 				t.Fatalf("expected category %q, got %q (scores=%+v)", tt.category, got, scores)
 			}
 		})
 	}
 }
+
+func TestDefaultScorerTestRatio(t *testing.T) {
+	scorer := NewDefaultScorer(config.DefaultScoringConfig())
+
+	base := scorer.Score(model.ProjectMetrics{HasTests: true})
+	withRatio := scorer.Score(model.ProjectMetrics{HasTests: true, TestRatio: 0.6})
+	if withRatio.Polish <= base.Polish {
+		t.Fatalf("expected test ratio to add polish points (base=%d, ratio=%d)", base.Polish, withRatio.Polish)
+	}
+}
+
+func TestDefaultScorerExplain(t *testing.T) {
+	now := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	scorer := NewDefaultScorer(config.DefaultScoringConfig())
+	scorer.Now = func() time.Time { return now }
+
+	metrics := model.ProjectMetrics{
+		CommitCount: 10,
+		ActiveDays:  3,
+		HasREADME:   true,
+		LastTouched: now,
+	}
+	scores := scorer.Score(metrics)
+	explanation := scorer.Explain(&model.Project{Scores: scores, Metrics: metrics})
+
+	total := 0
+	for _, c := range explanation.Components {
+		total += c.Points
+		if c.Rule == "commit" && (c.Points != 5 || c.Detail != ">= 5") {
+			t.Fatalf("unexpected commit component: %+v", c)
+		}
+	}
+	if total != scores.Overall {
+		t.Fatalf("components sum to %d, overall is %d", total, scores.Overall)
+	}
+
+	checks := explanation.Categories
+	if len(checks) != 2 || checks[0].Category != "Experiment" || checks[0].Matched {
+		t.Fatalf("expected Experiment to fail first, got %+v", checks)
+	}
+	if want := "commits 10 > commitMax 4"; len(checks[0].Failed) == 0 || checks[0].Failed[0] != want {
+		t.Fatalf("expected first failed bound %q, got %v", want, checks[0].Failed)
+	}
+	if last := checks[len(checks)-1]; last.Category != scorer.Categorize(&model.Project{Scores: scores, Metrics: metrics}) || !last.Matched {
+		t.Fatalf("expected the last check to be the matched category, got %+v", last)
+	}
+}
+
+func TestDefaultScorerCustomCategories(t *testing.T) {
+	cfg := config.DefaultScoringConfig()
+	one := 1
+	cfg.Categories = config.CategoryConfig{
+		{Name: "Showcase", PolishMin: &one},
+		{Name: "Abandoned", CommitMax: &one},
+	}
+	scorer := NewDefaultScorer(cfg)
+
+	tests := []struct {
+		metrics  model.ProjectMetrics
+		category string
+	}{
+		{model.ProjectMetrics{HasREADME: true, CommitCount: 1}, "Showcase"},
+		{model.ProjectMetrics{CommitCount: 1}, "Abandoned"},
+		{model.ProjectMetrics{CommitCount: 50}, ""},
+	}
+	for _, tt := range tests {
+		scores := scorer.Score(tt.metrics)
+		if got := scorer.Categorize(&model.Project{Scores: scores, Metrics: tt.metrics}); got != tt.category {
+			t.Fatalf("metrics %+v: expected %q, got %q", tt.metrics, tt.category, got)
+		}
+	}
+}
+
+func TestDefaultScorerExpressions(t *testing.T) {
+	now := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	cfg := config.DefaultScoringConfig()
+	cfg.Custom = []config.CustomComponent{
+		{Name: "go-veteran", Score: "effort", When: `"Go" in languages && commits > 50`, Points: 4},
+	}
+	cfg.Categories = config.CategoryConfig{
+		{Name: "Abandoned", When: `!hasTests && ageDays > 365`},
+		{Name: "Active"},
+	}
+	scorer := NewDefaultScorer(cfg)
+	scorer.Now = func() time.Time { return now }
+	if err := scorer.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	old := model.ProjectMetrics{CommitCount: 60, Languages: []string{"Go"}, LastTouched: now.AddDate(-2, 0, 0)}
+	scores := scorer.Score(old)
+	if want := 10 + 4; scores.Effort != want {
+		t.Fatalf("expected effort %d including the custom component, got %d", want, scores.Effort)
+	}
+	if got := scorer.Categorize(&model.Project{Scores: scores, Metrics: old}); got != "Abandoned" {
+		t.Fatalf("expected Abandoned, got %q", got)
+	}
+
+	recent := model.ProjectMetrics{CommitCount: 60, Languages: []string{"Rust"}, LastTouched: now}
+	scores = scorer.Score(recent)
+	explanation := scorer.Explain(&model.Project{Scores: scores, Metrics: recent})
+	if got := explanation.Categories[0].Failed; len(got) != 1 || got[0] != "when !hasTests && ageDays > 365" {
+		t.Fatalf("expected the when clause to be reported as failed, got %v", got)
+	}
+	if got := scorer.Categorize(&model.Project{Scores: scores, Metrics: recent}); got != "Active" {
+		t.Fatalf("expected Active, got %q", got)
+	}
+}
+
+func TestDefaultScorerValidate(t *testing.T) {
+	cfg := config.DefaultScoringConfig()
+	cfg.Custom = []config.CustomComponent{{Name: "bad", Score: "effort", When: "commits >"}}
+	cfg.Categories = config.CategoryConfig{{Name: "Odd", When: "effort > 2 && polsh < 1"}}
+
+	err := NewDefaultScorer(cfg).Validate()
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	for _, want := range []string{`custom component "bad": 1:10: unexpected end of expression`, `category "Odd": 1:15: unknown identifier "polsh"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %q", want, err.Error())
+		}
+	}
+}
+
+func TestDefaultScorerCurves(t *testing.T) {
+	now := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	cfg := config.DefaultScoringConfig()
+	cfg.Effort.CommitCurve = &config.Curve{Type: "logarithmic", Points: 15, Saturation: 100}
+	cfg.Effort.Active = nil
+
+	tests := []struct {
+		curve   config.Curve
+		ageDays int
+		recency int
+	}{
+		{config.Curve{Type: "linear", Points: 6, MaxDays: 360}, 180, 3},
+		{config.Curve{Type: "linear", Points: 6, MaxDays: 360}, 720, 0},
+		{config.Curve{Type: "exponential", Points: 8, HalfLife: 90}, 180, 2},
+		{config.Curve{Type: "logistic", Points: 10, Midpoint: 365}, 365, 5},
+		{config.Curve{Type: "logistic", Points: 10, Midpoint: 365}, 0, 10},
+	}
+	for _, tt := range tests {
+		curve := tt.curve
+		cfg.Recency = config.RecencyConfig{Curve: &curve}
+		scorer := NewDefaultScorer(cfg)
+		scorer.Now = func() time.Time { return now }
+		if err := scorer.Validate(); err != nil {
+			t.Fatalf("Validate returned error: %v", err)
+		}
+		scores := scorer.Score(model.ProjectMetrics{CommitCount: 9, LastTouched: now.AddDate(0, 0, -tt.ageDays)})
+		if scores.Recency != tt.recency {
+			t.Fatalf("%s curve at %d days: expected %d, got %d", tt.curve.Type, tt.ageDays, tt.recency, scores.Recency)
+		}
+		// log(1+9)/log(1+100) of 15 points.
+		if scores.Effort != 7 {
+			t.Fatalf("expected logarithmic effort 7, got %d", scores.Effort)
+		}
+	}
+
+	cfg.Recency = config.RecencyConfig{Curve: &config.Curve{Type: "logarithmic", Points: 5, Saturation: 10}}
+	if err := NewDefaultScorer(cfg).Validate(); err == nil || !strings.Contains(err.Error(), "recency.curve") {
+		t.Fatalf("expected a recency.curve error, got %v", err)
+	}
+}
+
+func TestDefaultScorerNormalizes(t *testing.T) {
+	scorer := NewDefaultScorer(config.DefaultScoringConfig())
+	scorer.Now = func() time.Time { return time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC) }
+
+	full := model.ProjectMetrics{
+		CommitCount: 500, ActiveDays: 400, TestRatio: 1,
+		HasREADME: true, HasTests: true, HasCI: true, HasDocker: true, HasLicense: true,
+		LastTouched: scorer.Now(),
+	}
+	scores := scorer.Score(full)
+	if scores.Max != 39 || scores.Overall != scores.Max || scores.Normalized != 100 {
+		t.Fatalf("expected a perfect project to score 39/39 = 100, got %+v", scores)
+	}
+	if scores := scorer.Score(model.ProjectMetrics{CommitCount: 5}); scores.Normalized != 13 {
+		t.Fatalf("expected 5/39 to normalize to 13, got %+v", scores)
+	}
+}
+
+func TestRank(t *testing.T) {
+	var projects []*model.Project
+	for _, overall := range []int{10, 20, 20, 40} {
+		projects = append(projects, &model.Project{Scores: model.ProjectScores{Overall: overall}})
+	}
+	Rank(projects)
+
+	var got []int
+	for _, p := range projects {
+		got = append(got, p.Scores.Percentile)
+	}
+	if want := []int{13, 50, 50, 88}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected percentiles %v, got %v", want, got)
+	}
+}
+
+func TestDefaultScorerHonorsPinnedCategory(t *testing.T) {
+	scorer := NewDefaultScorer(config.DefaultScoringConfig())
+	metrics := model.ProjectMetrics{CommitCount: 2}
+	project := &model.Project{Metrics: metrics, Scores: scorer.Score(metrics)}
+	unpinned := scorer.Categorize(project)
+	if unpinned == "" || unpinned == "Showcase" {
+		t.Fatalf("expected a low-effort project to fall outside Showcase, got %q", unpinned)
+	}
+
+	project.PinnedCategory = "Showcase"
+	if got := scorer.Categorize(project); got != "Showcase" {
+		t.Fatalf("expected pinned category, got %q", got)
+	}
+	checks := scorer.Explain(project).Categories
+	if len(checks) != 1 || !checks[0].Pinned || checks[0].Category != "Showcase" {
+		t.Fatalf("expected a single pinned check, got %+v", checks)
+	}
+}
+
+func TestEmbeddedProfilesValidate(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, name := range cfg.ProfileNames() {
+		sc, err := cfg.ScoringProfile(name)
+		if err != nil {
+			t.Fatalf("profile %s: %v", name, err)
+		}
+		if err := NewDefaultScorer(sc).Validate(); err != nil {
+			t.Fatalf("profile %s does not validate: %v", name, err)
+		}
+	}
+}